@@ -6,17 +6,19 @@ import (
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
-	Short: "Generate new resources (app, repo)",
+	Short: "Generate new resources (app, repo, apikey)",
 	Long: `Generate new resources for your microservice project.
 
 Available subcommands:
   app     Generate a new application
   repo    Generate a new repository
+  apikey  Mint a new API key
 
 Examples:
   uranus generate app --name my_app --module github.com/my_org/my_app
   uranus generate repo --name my_repo
-  uranus generate repo --name path/to/my_repo`,
+  uranus generate repo --name path/to/my_repo
+  uranus generate apikey --owner partner-a --scopes orders:read`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
@@ -25,4 +27,5 @@ Examples:
 func init() {
 	generateCmd.AddCommand(generateAppCmd)
 	generateCmd.AddCommand(generateRepoCmd)
+	generateCmd.AddCommand(generateApiKeyCmd)
 }
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/apikey"
+)
+
+var (
+	apiKeyOwner  string
+	apiKeyScopes []string
+	apiKeyTTL    time.Duration
+	apiKeyFile   string
+)
+
+var generateApiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Mint a new API key",
+	Long: `Mint a new API key and print its hashed store entry.
+
+The plaintext key is printed once and is never stored, hand it to the partner.
+The store entry only holds the hash of the key. With --file it is appended to
+a key file served by apikey.NewFileStore, otherwise paste it into your store.
+
+Examples:
+  uranus generate apikey --owner partner-a --scopes orders:read,orders:write
+  uranus generate apikey -o partner-b -s orders:read --ttl 720h --file configs/api_keys.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiKeyOwner == "" {
+			fmt.Println("Error: --owner flag is required")
+			cmd.Help()
+			os.Exit(1)
+		}
+
+		if err := generateApiKey(apiKeyOwner, apiKeyScopes, apiKeyTTL, apiKeyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating api key: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	generateApiKeyCmd.Flags().StringVarP(&apiKeyOwner, "owner", "o", "", "Owner of the key (required)")
+	generateApiKeyCmd.MarkFlagRequired("owner")
+
+	generateApiKeyCmd.Flags().StringSliceVarP(&apiKeyScopes, "scopes", "s", nil, "Comma separated scopes granted to the key")
+	generateApiKeyCmd.Flags().DurationVarP(&apiKeyTTL, "ttl", "t", 0, "Lifetime of the key, never expires when empty")
+	generateApiKeyCmd.Flags().StringVarP(&apiKeyFile, "file", "f", "", "Key file to append the hashed key to")
+}
+
+func generateApiKey(owner string, scopes []string, ttl time.Duration, file string) error {
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return fmt.Errorf("owner cannot be empty")
+	}
+
+	plaintext, key := apikey.Mint(owner, scopes, ttl)
+
+	fmt.Printf("🔑 API key for %s:\n\n", owner)
+	fmt.Printf("  %s\n\n", plaintext)
+	fmt.Println("⚠️  This is the only time the key is shown, store it safely.")
+
+	if file == "" {
+		entry, err := yaml.Marshal(apikey.KeyFile{Keys: []apikey.Key{key}})
+		if err != nil {
+			return fmt.Errorf("failed to encode key: %w", err)
+		}
+		fmt.Printf("\nStore entry:\n\n%s", entry)
+		return nil
+	}
+
+	keyFile, err := apikey.LoadKeyFile(file)
+	if err != nil {
+		return err
+	}
+	keyFile.Keys = append(keyFile.Keys, key)
+	if err := apikey.SaveKeyFile(file, keyFile); err != nil {
+		return err
+	}
+	fmt.Printf("\n✅ Key %s added to %s\n", key.ID, file)
+	return nil
+}
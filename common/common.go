@@ -2,3 +2,4 @@ package common
 
 const LogKeyAppName = "app_name"
 const CorrelationIdKey = "correlation_id"
+const TenantIdKey = "x-tenant-id"
@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	_ "google.golang.org/grpc/balancer/leastrequest"
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	StaticScheme = "static"
+	FileScheme   = "file"
+	DnsSrvScheme = "dnssrv"
+)
+
+// Balancer is the load balancing policy used across the resolved endpoints.
+type Balancer string
+
+const (
+	RoundRobin   Balancer = "round_robin"
+	LeastRequest Balancer = "least_request_experimental"
+	PickFirst    Balancer = "pick_first"
+)
+
+// ServiceConfig returns the default gRPC service config selecting the balancer.
+func (b Balancer) ServiceConfig() string {
+	return fmt.Sprintf(`{"loadBalancingConfig":[{"%s":{}}]}`, b)
+}
+
+var (
+	defaultOptions = &options{}
+)
+
+type options struct {
+	pollInterval time.Duration
+	zapLog       *zap.Logger
+}
+
+func evaluateOptions(opts []Option, defaultPollInterval time.Duration) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	for _, o := range opts {
+		o(optCopy)
+	}
+	if optCopy.pollInterval <= 0 {
+		optCopy.pollInterval = defaultPollInterval
+	}
+	if optCopy.zapLog == nil {
+		optCopy.zapLog, _ = zap.NewProduction()
+	}
+	return optCopy
+}
+
+type Option func(*options)
+
+// WithPollInterval sets how often the file resolver checks its file and the DNS resolver
+// re-resolves its name.
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = interval
+	}
+}
+
+// WithZapLog sets the logger used to report endpoint changes and resolution errors.
+func WithZapLog(zapLog *zap.Logger) Option {
+	return func(o *options) {
+		o.zapLog = zapLog
+	}
+}
+
+// toState converts a list of "host:port" endpoints to a resolver state.
+func toState(endpoints []string) resolver.State {
+	state := resolver.State{
+		Addresses: make([]resolver.Address, 0, len(endpoints)),
+		Endpoints: make([]resolver.Endpoint, 0, len(endpoints)),
+	}
+	for _, endpoint := range endpoints {
+		addr := resolver.Address{Addr: endpoint}
+		state.Addresses = append(state.Addresses, addr)
+		state.Endpoints = append(state.Endpoints, resolver.Endpoint{Addresses: []resolver.Address{addr}})
+	}
+	return state
+}
@@ -0,0 +1,113 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	defaultDnsPollInterval = 30 * time.Second
+	dnsLookupTimeout       = 10 * time.Second
+)
+
+// DnsSrvBuilder resolves "dnssrv:///_grpc._tcp.my-service.my-namespace.svc.cluster.local"
+// targets with DNS SRV records, so that both hosts and ports come from DNS.
+// The name is re-resolved periodically and whenever the connection asks for it.
+type DnsSrvBuilder struct {
+	options *options
+}
+
+func NewDnsSrvBuilder(opts ...Option) *DnsSrvBuilder {
+	return &DnsSrvBuilder{options: evaluateOptions(opts, defaultDnsPollInterval)}
+}
+
+func (b *DnsSrvBuilder) Scheme() string {
+	return DnsSrvScheme
+}
+
+func (b *DnsSrvBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	name := target.Endpoint()
+	if name == "" {
+		return nil, fmt.Errorf("dns srv target requires a name")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &dnsSrvResolver{
+		options: b.options,
+		name:    name,
+		cc:      cc,
+		ctx:     ctx,
+		cancel:  cancel,
+		resolve: make(chan struct{}, 1),
+	}
+	go r.watch()
+	return r, nil
+}
+
+type dnsSrvResolver struct {
+	*options
+	name      string
+	cc        resolver.ClientConn
+	ctx       context.Context
+	cancel    context.CancelFunc
+	resolve   chan struct{}
+	endpoints []string
+}
+
+func (r *dnsSrvResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolve <- struct{}{}:
+	default:
+	}
+}
+
+func (r *dnsSrvResolver) Close() {
+	r.cancel()
+}
+
+func (r *dnsSrvResolver) watch() {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.lookup(); err != nil {
+			r.zapLog.Warn("failed to resolve dns srv", zap.String("name", r.name), zap.Error(err))
+			r.cc.ReportError(err)
+		}
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolve:
+		}
+	}
+}
+
+func (r *dnsSrvResolver) lookup() error {
+	ctx, cancel := context.WithTimeout(r.ctx, dnsLookupTimeout)
+	defer cancel()
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", r.name)
+	if err != nil {
+		return err
+	}
+
+	endpoints := make([]string, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		endpoints = append(endpoints, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+	}
+	slices.Sort(endpoints)
+	if slices.Equal(endpoints, r.endpoints) {
+		return nil
+	}
+
+	r.endpoints = endpoints
+	r.zapLog.Info("endpoints updated", zap.String("name", r.name), zap.Strings("endpoints", endpoints))
+	return r.cc.UpdateState(toState(endpoints))
+}
@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/resolver"
+)
+
+const defaultFilePollInterval = 2 * time.Second
+
+// EndpointFile is the content of a file watched by the file resolver, in YAML or JSON:
+//
+//	endpoints:
+//	  - 127.0.0.1:9001
+//	  - 127.0.0.1:9002
+type EndpointFile struct {
+	Endpoints []string `yaml:"endpoints" json:"endpoints"`
+}
+
+// LoadEndpointFile reads an endpoint file, JSON being a subset of YAML both are accepted.
+func LoadEndpointFile(path string) (*EndpointFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read endpoint file %s: %w", path, err)
+	}
+	return parseEndpointFile(path, content)
+}
+
+func parseEndpointFile(path string, content []byte) (*EndpointFile, error) {
+	file := &EndpointFile{}
+	if err := yaml.Unmarshal(content, file); err != nil {
+		return nil, fmt.Errorf("failed to parse endpoint file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// FileBuilder resolves "file:///path/to/endpoints.yaml" targets. The file is polled and
+// every change is pushed to the connection, which lets failover be tested locally
+// by editing the file.
+type FileBuilder struct {
+	options *options
+}
+
+func NewFileBuilder(opts ...Option) *FileBuilder {
+	return &FileBuilder{options: evaluateOptions(opts, defaultFilePollInterval)}
+}
+
+func (b *FileBuilder) Scheme() string {
+	return FileScheme
+}
+
+func (b *FileBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	path := target.URL.Path
+	if path == "" {
+		path = target.URL.Opaque
+	}
+	r := &fileResolver{
+		options: b.options,
+		path:    path,
+		cc:      cc,
+		resolve: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	go r.watch()
+	return r, nil
+}
+
+type fileResolver struct {
+	*options
+	path      string
+	cc        resolver.ClientConn
+	content   []byte
+	resolve   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (r *fileResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolve <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops polling the file, gRPC may call it more than once.
+func (r *fileResolver) Close() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+}
+
+func (r *fileResolver) watch() {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		case <-r.resolve:
+		}
+		if err := r.load(); err != nil {
+			// keep the previous endpoints, a half-written file must not drop every connection
+			r.zapLog.Warn("failed to reload endpoint file", zap.String("path", r.path), zap.Error(err))
+			r.cc.ReportError(err)
+		}
+	}
+}
+
+// load pushes the endpoints of the file to the connection when its content changed.
+func (r *fileResolver) load() error {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to read endpoint file %s: %w", r.path, err)
+	}
+	if r.content != nil && bytes.Equal(content, r.content) {
+		return nil
+	}
+	file, err := parseEndpointFile(r.path, content)
+	if err != nil {
+		return err
+	}
+	if len(file.Endpoints) == 0 {
+		return fmt.Errorf("endpoint file %s has no endpoints", r.path)
+	}
+
+	r.content = content
+	endpoints := slices.Clone(file.Endpoints)
+	r.zapLog.Info("endpoints updated", zap.String("path", r.path), zap.Strings("endpoints", endpoints))
+	return r.cc.UpdateState(toState(endpoints))
+}
@@ -0,0 +1,188 @@
+package discovery
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/resolver"
+)
+
+// fakeClientConn records the states and errors pushed by a resolver.
+type fakeClientConn struct {
+	resolver.ClientConn
+	states chan resolver.State
+	errs   chan error
+}
+
+func newFakeClientConn() *fakeClientConn {
+	return &fakeClientConn{states: make(chan resolver.State, 10), errs: make(chan error, 10)}
+}
+
+func (cc *fakeClientConn) UpdateState(state resolver.State) error {
+	cc.states <- state
+	return nil
+}
+
+func (cc *fakeClientConn) ReportError(err error) {
+	cc.errs <- err
+}
+
+func (cc *fakeClientConn) nextState(t *testing.T) []string {
+	t.Helper()
+	select {
+	case state := <-cc.states:
+		addrs := make([]string, 0, len(state.Addresses))
+		for _, addr := range state.Addresses {
+			addrs = append(addrs, addr.Addr)
+		}
+		if len(state.Endpoints) != len(addrs) {
+			t.Errorf("%d endpoints for %d addresses", len(state.Endpoints), len(addrs))
+		}
+		return addrs
+	case <-time.After(5 * time.Second):
+		t.Fatal("no state pushed to the connection")
+		return nil
+	}
+}
+
+func (cc *fakeClientConn) nextError(t *testing.T) error {
+	t.Helper()
+	select {
+	case err := <-cc.errs:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("no error reported to the connection")
+		return nil
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseEndpointFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{name: "yaml", content: "endpoints:\n  - 127.0.0.1:9001\n  - 127.0.0.1:9002\n", want: []string{"127.0.0.1:9001", "127.0.0.1:9002"}},
+		{name: "json", content: `{"endpoints":["127.0.0.1:9001"]}`, want: []string{"127.0.0.1:9001"}},
+		{name: "no endpoints", content: "other: 1\n"},
+		{name: "invalid", content: "endpoints: [127.0.0.1:9001", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := parseEndpointFile("endpoints.yaml", []byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %t", err, tt.wantErr)
+			}
+			if err == nil && !slices.Equal(file.Endpoints, tt.want) {
+				t.Errorf("endpoints = %v, want %v", file.Endpoints, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadEndpointFileMissing(t *testing.T) {
+	if _, err := LoadEndpointFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
+
+func buildFileResolver(t *testing.T, path string, cc resolver.ClientConn) (resolver.Resolver, error) {
+	t.Helper()
+	builder := NewFileBuilder(WithPollInterval(time.Hour), WithZapLog(zap.NewNop()))
+	return builder.Build(resolver.Target{URL: url.URL{Scheme: FileScheme, Path: path}}, cc, resolver.BuildOptions{})
+}
+
+func TestFileResolverPushesUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	writeFile(t, path, "endpoints:\n  - 127.0.0.1:9001\n")
+	cc := newFakeClientConn()
+	r, err := buildFileResolver(t, path, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got := cc.nextState(t); !slices.Equal(got, []string{"127.0.0.1:9001"}) {
+		t.Fatalf("initial endpoints = %v", got)
+	}
+
+	writeFile(t, path, "endpoints:\n  - 127.0.0.1:9001\n  - 127.0.0.1:9002\n")
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	if got := cc.nextState(t); !slices.Equal(got, []string{"127.0.0.1:9001", "127.0.0.1:9002"}) {
+		t.Fatalf("reloaded endpoints = %v", got)
+	}
+
+	// a broken file is reported and keeps the previous endpoints
+	writeFile(t, path, "endpoints: [127.0.0.1:9003")
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	cc.nextError(t)
+	writeFile(t, path, "endpoints: []\n")
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	cc.nextError(t)
+	select {
+	case state := <-cc.states:
+		t.Fatalf("state %v pushed for an invalid file", state)
+	default:
+	}
+
+	writeFile(t, path, "endpoints:\n  - 127.0.0.1:9003\n")
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	if got := cc.nextState(t); !slices.Equal(got, []string{"127.0.0.1:9003"}) {
+		t.Fatalf("recovered endpoints = %v", got)
+	}
+}
+
+func TestFileResolverSkipsUnchangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	writeFile(t, path, "endpoints:\n  - 127.0.0.1:9001\n")
+	cc := newFakeClientConn()
+	r := &fileResolver{options: evaluateOptions([]Option{WithZapLog(zap.NewNop())}, defaultFilePollInterval), path: path, cc: cc}
+	for range 2 {
+		if err := r.load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cc.nextState(t)
+	if len(cc.states) != 0 {
+		t.Errorf("%d states pushed for an unchanged file", len(cc.states)+1)
+	}
+}
+
+func TestFileResolverBuildErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.yaml")
+	writeFile(t, empty, "endpoints: []\n")
+	for name, path := range map[string]string{
+		"missing file": filepath.Join(dir, "missing.yaml"),
+		"no endpoints": empty,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := buildFileResolver(t, path, newFakeClientConn()); err == nil {
+				t.Error("no error building the resolver")
+			}
+		})
+	}
+}
+
+func TestFileResolverCloseTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	writeFile(t, path, "endpoints:\n  - 127.0.0.1:9001\n")
+	r, err := buildFileResolver(t, path, newFakeClientConn())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	r.Close()
+}
@@ -0,0 +1,82 @@
+package discovery
+
+import (
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// StaticBuilder resolves "static:///host1:port,host2:port" targets.
+// UpdateEndpoints swaps the endpoints of every connection built by it, which
+// replaces the ones listed in the target.
+type StaticBuilder struct {
+	mu        sync.Mutex
+	endpoints []string
+	resolvers map[*staticResolver]struct{}
+}
+
+func NewStaticBuilder() *StaticBuilder {
+	return &StaticBuilder{resolvers: make(map[*staticResolver]struct{})}
+}
+
+func (b *StaticBuilder) Scheme() string {
+	return StaticScheme
+}
+
+func (b *StaticBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	r := &staticResolver{builder: b, cc: cc}
+	endpoints := splitEndpoints(target.Endpoint())
+	b.mu.Lock()
+	if b.endpoints != nil {
+		endpoints = b.endpoints
+	}
+	b.resolvers[r] = struct{}{}
+	b.mu.Unlock()
+
+	if err := cc.UpdateState(toState(endpoints)); err != nil {
+		cc.ReportError(err)
+	}
+	return r, nil
+}
+
+// UpdateEndpoints pushes a new endpoint list to all live connections.
+func (b *StaticBuilder) UpdateEndpoints(endpoints []string) {
+	b.mu.Lock()
+	b.endpoints = endpoints
+	resolvers := make([]*staticResolver, 0, len(b.resolvers))
+	for r := range b.resolvers {
+		resolvers = append(resolvers, r)
+	}
+	b.mu.Unlock()
+
+	// update outside the lock, the client connection may close the resolver concurrently
+	for _, r := range resolvers {
+		if err := r.cc.UpdateState(toState(endpoints)); err != nil {
+			r.cc.ReportError(err)
+		}
+	}
+}
+
+type staticResolver struct {
+	builder *StaticBuilder
+	cc      resolver.ClientConn
+}
+
+func (r *staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *staticResolver) Close() {
+	r.builder.mu.Lock()
+	defer r.builder.mu.Unlock()
+	delete(r.builder.resolvers, r)
+}
+
+func splitEndpoints(s string) []string {
+	var endpoints []string
+	for _, endpoint := range strings.Split(s, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var allCodes = []codes.Code{
+	codes.OK, codes.Canceled, codes.Unknown, codes.InvalidArgument, codes.DeadlineExceeded,
+	codes.NotFound, codes.AlreadyExists, codes.PermissionDenied, codes.ResourceExhausted,
+	codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unimplemented,
+	codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unauthenticated,
+}
+
+func TestGrpcCodeMapping(t *testing.T) {
+	// lossy maps the gRPC codes that do not survive FromGrpcCode then ToGrpcCode to the code they come back as
+	lossy := map[codes.Code]codes.Code{
+		codes.Unknown:            codes.Internal,
+		codes.AlreadyExists:      codes.Aborted,
+		codes.FailedPrecondition: codes.InvalidArgument,
+		codes.OutOfRange:         codes.InvalidArgument,
+		codes.DataLoss:           codes.Internal,
+	}
+	for _, code := range allCodes {
+		t.Run(code.String(), func(t *testing.T) {
+			want := code
+			if back, ok := lossy[code]; ok {
+				want = back
+			}
+			if got := ToGrpcCode(FromGrpcCode(code)); got != want {
+				t.Errorf("ToGrpcCode(FromGrpcCode(%s)) = %s, want %s", code, got, want)
+			}
+		})
+	}
+}
+
+func TestHttpStatusMapping(t *testing.T) {
+	tests := []struct {
+		status int
+		want   int
+	}{
+		{status: http.StatusOK, want: http.StatusOK},
+		{status: http.StatusBadRequest, want: http.StatusBadRequest},
+		{status: http.StatusUnauthorized, want: http.StatusUnauthorized},
+		{status: http.StatusForbidden, want: http.StatusForbidden},
+		{status: http.StatusNotFound, want: http.StatusNotFound},
+		{status: http.StatusConflict, want: http.StatusConflict},
+		{status: http.StatusTooManyRequests, want: http.StatusTooManyRequests},
+		{status: ClientClosed, want: ClientClosed},
+		{status: http.StatusInternalServerError, want: http.StatusInternalServerError},
+		{status: http.StatusNotImplemented, want: http.StatusNotImplemented},
+		{status: http.StatusServiceUnavailable, want: http.StatusServiceUnavailable},
+		{status: http.StatusGatewayTimeout, want: http.StatusGatewayTimeout},
+		// lossy pairs
+		{status: http.StatusPreconditionFailed, want: http.StatusBadRequest},
+		{status: http.StatusUnprocessableEntity, want: http.StatusBadRequest},
+		{status: http.StatusRequestedRangeNotSatisfiable, want: http.StatusBadRequest},
+		{status: http.StatusTeapot, want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			if got := FromGrpcCode(ToGrpcCode(tt.status)); got != tt.want {
+				t.Errorf("FromGrpcCode(ToGrpcCode(%d)) = %d, want %d", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromErrorKeepsGrpcCode(t *testing.T) {
+	for _, code := range allCodes[1:] {
+		t.Run(code.String(), func(t *testing.T) {
+			err := FromError(status.Error(code, "failed"))
+			if int(err.Code) != FromGrpcCode(code) {
+				t.Errorf("code = %d, want %d", err.Code, FromGrpcCode(code))
+			}
+			if got := status.Code(err); got != code {
+				t.Errorf("round-tripped code = %s, want %s", got, code)
+			}
+		})
+	}
+}
+
+func TestWithGrpcCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want codes.Code
+	}{
+		{name: "mapped by default", err: Conflict("USER_EXISTS", "user exists"), want: codes.Aborted},
+		{
+			name: "code of the same status",
+			err:  Conflict("USER_EXISTS", "user exists").WithGrpcCode(codes.AlreadyExists),
+			want: codes.AlreadyExists,
+		},
+		{
+			name: "code of another status ignored",
+			err:  NotFound("USER_NOT_FOUND", "user not found").WithGrpcCode(codes.AlreadyExists),
+			want: codes.NotFound,
+		},
+		{
+			name: "kept by copies",
+			err:  Conflict("USER_EXISTS", "user exists").WithGrpcCode(codes.AlreadyExists).WithMetadata(map[string]string{"id": "1"}),
+			want: codes.AlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.err); got != tt.want {
+				t.Errorf("code = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
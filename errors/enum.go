@@ -0,0 +1,85 @@
+package errors
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Field numbers of the options declared in grpc_third_party/errors/errors.proto.
+const (
+	defaultCodeField protowire.Number = 1108
+	codeField        protowire.Number = 1109
+)
+
+// FromEnum returns the error of a reason enum declared with the errors.proto options.
+// The reason is the name of the value and the code its (errors.code) option, falling back
+// to the (errors.default_code) of the enum and then to UnknownCode.
+//
+// Example:
+//
+//	enum ErrorReason {
+//	  option (errors.default_code) = 500;
+//	  USER_NOT_FOUND = 0 [(errors.code) = 404];
+//	}
+//
+//	errors.FromEnum(pb.ErrorReason_USER_NOT_FOUND, "user not found")
+func FromEnum(value protoreflect.Enum, message string) *Error {
+	descriptor := value.Descriptor()
+	code := UnknownCode
+	if c, ok := int32Option(descriptor.Options(), defaultCodeField); ok {
+		code = int(c)
+	}
+	valueDescriptor := descriptor.Values().ByNumber(value.Number())
+	if valueDescriptor == nil {
+		return New(code, UnknownReason, message)
+	}
+	if c, ok := int32Option(valueDescriptor.Options(), codeField); ok {
+		code = int(c)
+	}
+	return New(code, string(valueDescriptor.Name()), message)
+}
+
+// FromEnumf is FromEnum with a formatted message.
+func FromEnumf(value protoreflect.Enum, format string, a ...interface{}) *Error {
+	return FromEnum(value, fmt.Sprintf(format, a...))
+}
+
+// int32Option reads an int32 option by field number. The options are scanned in their wire format
+// so that it works whether the errors.proto extensions are registered in this binary or not.
+func int32Option(options proto.Message, field protowire.Number) (int32, bool) {
+	if options == nil {
+		return 0, false
+	}
+	b, err := proto.Marshal(options)
+	if err != nil {
+		return 0, false
+	}
+	var value int32
+	found := false
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return 0, false
+		}
+		b = b[n:]
+		if num == field && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return 0, false
+			}
+			// the last occurrence wins, as when unmarshalling
+			value, found = int32(v), true
+			b = b[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return 0, false
+		}
+		b = b[n:]
+	}
+	return value, found
+}
@@ -0,0 +1,213 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const (
+	// UnknownCode is the code of errors that are not an *Error.
+	UnknownCode = http.StatusInternalServerError
+	// UnknownReason is the reason of errors that are not an *Error.
+	UnknownReason = ""
+)
+
+// Error is an error with an HTTP status code, a machine readable reason, a message for the client
+// and metadata. It converts to a gRPC status carrying an ErrorInfo detail, see GRPCStatus.
+type Error struct {
+	Code     int32
+	Reason   string
+	Message  string
+	Metadata map[string]string
+	// Violations lists the invalid fields of a bad request, carried in a BadRequest detail in gRPC.
+	Violations []FieldViolation
+	// grpcCode is the gRPC code of the error when it is not the one mapped from Code,
+	// AlreadyExists rather than Aborted for a 409 for instance.
+	grpcCode codes.Code
+	cause    error
+}
+
+// FieldViolation describes an invalid field of a request. Fields of nested messages are reported
+// with their full path ("address.zip_code").
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// New returns an error with the HTTP status code, reason and message.
+func New(code int, reason, message string) *Error {
+	return &Error{
+		Code:    int32(code),
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// Newf is New with a formatted message.
+func Newf(code int, reason, format string, a ...interface{}) *Error {
+	return New(code, reason, fmt.Sprintf(format, a...))
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("error: code = %d reason = %s message = %s metadata = %v cause = %v", e.Code, e.Reason, e.Message, e.Metadata, e.cause)
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches errors with the same code and reason, so that errors.Is(err, ErrUserNotFound) holds
+// whatever their message and metadata.
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if errors.As(target, &t) {
+		return t.Code == e.Code && t.Reason == e.Reason
+	}
+	return false
+}
+
+// WithCause returns a copy of the error wrapping cause. The cause is never sent to clients.
+func (e *Error) WithCause(cause error) *Error {
+	err := e.clone()
+	err.cause = cause
+	return err
+}
+
+// WithViolations returns a copy of the error with the field violations.
+func (e *Error) WithViolations(violations ...FieldViolation) *Error {
+	err := e.clone()
+	err.Violations = violations
+	return err
+}
+
+// WithGrpcCode returns a copy of the error converting to code in gRPC instead of the code mapped
+// from its HTTP status code, AlreadyExists rather than Aborted for a 409 for instance.
+// Codes that do not map to the HTTP status code of the error (see FromGrpcCode) are ignored.
+func (e *Error) WithGrpcCode(code codes.Code) *Error {
+	err := e.clone()
+	err.grpcCode = code
+	return err
+}
+
+// WithMetadata returns a copy of the error with metadata.
+func (e *Error) WithMetadata(metadata map[string]string) *Error {
+	err := e.clone()
+	err.Metadata = metadata
+	return err
+}
+
+func (e *Error) clone() *Error {
+	if e == nil {
+		return nil
+	}
+	return &Error{
+		Code:       e.Code,
+		Reason:     e.Reason,
+		Message:    e.Message,
+		Metadata:   maps.Clone(e.Metadata),
+		Violations: slices.Clone(e.Violations),
+		grpcCode:   e.grpcCode,
+		cause:      e.cause,
+	}
+}
+
+// GRPCStatus returns the gRPC status of the error, its code is mapped from the HTTP status code
+// unless set by WithGrpcCode or FromError, and its reason and metadata are carried in an ErrorInfo
+// detail, its violations in a BadRequest detail.
+func (e *Error) GRPCStatus() *status.Status {
+	s := status.New(e.GrpcCode(), e.Message)
+	var details []protoadapt.MessageV1
+	if e.Reason != "" || len(e.Metadata) > 0 {
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   e.Reason,
+			Metadata: e.Metadata,
+		})
+	}
+	if len(e.Violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range e.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if len(details) == 0 {
+		return s
+	}
+	withDetails, err := s.WithDetails(details...)
+	if err != nil {
+		return s
+	}
+	return withDetails
+}
+
+// GrpcCode returns the gRPC code of the error.
+func (e *Error) GrpcCode() codes.Code {
+	if e.grpcCode != codes.OK && FromGrpcCode(e.grpcCode) == int(e.Code) {
+		return e.grpcCode
+	}
+	return ToGrpcCode(int(e.Code))
+}
+
+// Code returns the HTTP status code of err, 200 when nil and UnknownCode when err is not an *Error.
+func Code(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	return int(FromError(err).Code)
+}
+
+// Reason returns the reason of err, UnknownReason when err is not an *Error.
+func Reason(err error) string {
+	if err == nil {
+		return UnknownReason
+	}
+	return FromError(err).Reason
+}
+
+// Clone returns a deep copy of err.
+func Clone(err *Error) *Error {
+	return err.clone()
+}
+
+// FromError converts err to an *Error. gRPC status errors keep their code, message, ErrorInfo and
+// BadRequest details, other errors become an UnknownCode error whose message is the one of err.
+// The gRPC code is kept along the HTTP status code so that the error converts back to the same status.
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return New(UnknownCode, UnknownReason, err.Error()).WithCause(err)
+	}
+	ret := New(FromGrpcCode(s.Code()), UnknownReason, s.Message())
+	ret.grpcCode = s.Code()
+	for _, detail := range s.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			ret.Reason = d.Reason
+			ret.Metadata = d.Metadata
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				ret.Violations = append(ret.Violations, FieldViolation{Field: v.GetField(), Description: v.GetDescription()})
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// HttpBody is the JSON body of HTTP errors.
+type HttpBody struct {
+	Error    string            `json:"error"`
+	Code     int32             `json:"code"`
+	Reason   string            `json:"reason,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Violations lists the invalid fields of a bad request.
+	Violations []FieldViolation `json:"violations,omitempty"`
+}
+
+// ToHttpBody returns the JSON body of e.
+func (e *Error) ToHttpBody() HttpBody {
+	return HttpBody{
+		Error:      e.Message,
+		Code:       e.Code,
+		Reason:     e.Reason,
+		Metadata:   e.Metadata,
+		Violations: e.Violations,
+	}
+}
+
+// HttpAbort aborts the request with the status code and JSON body of err.
+func HttpAbort(c *gin.Context, err error) {
+	e := FromError(err)
+	c.AbortWithStatusJSON(int(e.Code), e.ToHttpBody())
+}
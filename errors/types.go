@@ -0,0 +1,94 @@
+package errors
+
+import "net/http"
+
+// BadRequest returns a 400 error, InvalidArgument in gRPC.
+func BadRequest(reason, message string) *Error {
+	return New(http.StatusBadRequest, reason, message)
+}
+
+// IsBadRequest reports whether err is a 400 error.
+func IsBadRequest(err error) bool {
+	return Code(err) == http.StatusBadRequest
+}
+
+// Unauthorized returns a 401 error, Unauthenticated in gRPC.
+func Unauthorized(reason, message string) *Error {
+	return New(http.StatusUnauthorized, reason, message)
+}
+
+// IsUnauthorized reports whether err is a 401 error.
+func IsUnauthorized(err error) bool {
+	return Code(err) == http.StatusUnauthorized
+}
+
+// Forbidden returns a 403 error, PermissionDenied in gRPC.
+func Forbidden(reason, message string) *Error {
+	return New(http.StatusForbidden, reason, message)
+}
+
+// IsForbidden reports whether err is a 403 error.
+func IsForbidden(err error) bool {
+	return Code(err) == http.StatusForbidden
+}
+
+// NotFound returns a 404 error, NotFound in gRPC.
+func NotFound(reason, message string) *Error {
+	return New(http.StatusNotFound, reason, message)
+}
+
+// IsNotFound reports whether err is a 404 error.
+func IsNotFound(err error) bool {
+	return Code(err) == http.StatusNotFound
+}
+
+// Conflict returns a 409 error, Aborted in gRPC. Use WithGrpcCode(codes.AlreadyExists) for a
+// resource that already exists.
+func Conflict(reason, message string) *Error {
+	return New(http.StatusConflict, reason, message)
+}
+
+// IsConflict reports whether err is a 409 error.
+func IsConflict(err error) bool {
+	return Code(err) == http.StatusConflict
+}
+
+// TooManyRequests returns a 429 error, ResourceExhausted in gRPC.
+func TooManyRequests(reason, message string) *Error {
+	return New(http.StatusTooManyRequests, reason, message)
+}
+
+// IsTooManyRequests reports whether err is a 429 error.
+func IsTooManyRequests(err error) bool {
+	return Code(err) == http.StatusTooManyRequests
+}
+
+// InternalServer returns a 500 error, Internal in gRPC.
+func InternalServer(reason, message string) *Error {
+	return New(http.StatusInternalServerError, reason, message)
+}
+
+// IsInternalServer reports whether err is a 500 error.
+func IsInternalServer(err error) bool {
+	return Code(err) == http.StatusInternalServerError
+}
+
+// ServiceUnavailable returns a 503 error, Unavailable in gRPC.
+func ServiceUnavailable(reason, message string) *Error {
+	return New(http.StatusServiceUnavailable, reason, message)
+}
+
+// IsServiceUnavailable reports whether err is a 503 error.
+func IsServiceUnavailable(err error) bool {
+	return Code(err) == http.StatusServiceUnavailable
+}
+
+// GatewayTimeout returns a 504 error, DeadlineExceeded in gRPC.
+func GatewayTimeout(reason, message string) *Error {
+	return New(http.StatusGatewayTimeout, reason, message)
+}
+
+// IsGatewayTimeout reports whether err is a 504 error.
+func IsGatewayTimeout(err error) bool {
+	return Code(err) == http.StatusGatewayTimeout
+}
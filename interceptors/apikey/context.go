@@ -0,0 +1,42 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/authz"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+)
+
+// Identity describes the API key a request was authenticated with.
+type Identity struct {
+	KeyID  string
+	Owner  string
+	Scopes []string
+}
+
+type identityKey struct{}
+
+// ContextWithIdentity returns a copy of ctx carrying identity.
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	ctx = reqctx.Update(ctx, func(meta *reqctx.RequestMeta) {
+		meta.Subject = identity.Owner
+	})
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity of the API key the request was authenticated with.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// Principal lets authz.Authorize check the scopes of API keys:
+//
+//	authz.Authorize(authz.WithPolicyFile("policy.yaml"), authz.WithPrincipal(apikey.Principal))
+func Principal(ctx context.Context) authz.Principal {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return authz.Principal{}
+	}
+	return authz.Principal{Subject: identity.Owner, Scopes: identity.Scopes}
+}
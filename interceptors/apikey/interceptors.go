@@ -0,0 +1,125 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	uranuserrors "github.com/tqhuy-dev/xgen-uranus/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Reasons of the errors returned for rejected requests.
+const (
+	InvalidKeyReason       = "INVALID_API_KEY"
+	StoreUnavailableReason = "API_KEY_STORE_UNAVAILABLE"
+)
+
+var (
+	errMissingKey = errors.New("missing api key")
+	errInvalidKey = errors.New("invalid api key")
+)
+
+type authenticator struct {
+	*options
+}
+
+// authenticate verifies a plaintext key. Unknown, wrong, expired and disabled keys
+// all fail with the same error so that callers cannot probe key IDs.
+func (a *authenticator) authenticate(ctx context.Context, plaintext string) (*Identity, error) {
+	if plaintext == "" {
+		return nil, errMissingKey
+	}
+	id, secret, err := ParseKey(plaintext)
+	if err != nil {
+		return nil, errInvalidKey
+	}
+	key, err := a.store.Get(ctx, id)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, errInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !key.Verify(secret, time.Now()) {
+		return nil, errInvalidKey
+	}
+	return &Identity{KeyID: key.ID, Owner: key.Owner, Scopes: key.Scopes}, nil
+}
+
+func (a *authenticator) authenticateGrpc(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	plaintext := ""
+	if values := md.Get(a.header); len(values) > 0 {
+		plaintext = values[0]
+	}
+	identity, err := a.authenticate(ctx, plaintext)
+	if err != nil {
+		return nil, rejection(err)
+	}
+	return ContextWithIdentity(ctx, identity), nil
+}
+
+// rejection returns the error sent for a failed authentication: codes.Unauthenticated or 401 for
+// missing and invalid keys, codes.Unavailable or 503 when the store fails.
+func rejection(err error) *uranuserrors.Error {
+	if errors.Is(err, errMissingKey) || errors.Is(err, errInvalidKey) {
+		return uranuserrors.Unauthorized(InvalidKeyReason, err.Error())
+	}
+	return uranuserrors.ServiceUnavailable(StoreUnavailableReason, "api key store unavailable").WithCause(err)
+}
+
+// Authenticate returns a new unary server interceptor that requires a valid API key in the
+// "x-api-key" metadata and puts its identity in the context, see FromContext.
+func Authenticate(opts ...Option) grpc.UnaryServerInterceptor {
+	a := &authenticator{evaluateOptions(opts)}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := a.public[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+		newCtx, err := a.authenticateGrpc(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(newCtx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new streaming server interceptor that requires a valid API key.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	a := &authenticator{evaluateOptions(opts)}
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := a.public[info.FullMethod]; ok {
+			return handler(srv, stream)
+		}
+		newCtx, err := a.authenticateGrpc(stream.Context())
+		if err != nil {
+			return err
+		}
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = newCtx
+		return handler(srv, wrapped)
+	}
+}
+
+// HttpAuthenticate is a Gin middleware that requires a valid API key in the X-Api-Key header
+// and puts its identity in the request context, see FromContext.
+func HttpAuthenticate(opts ...Option) gin.HandlerFunc {
+	a := &authenticator{evaluateOptions(opts)}
+	return func(c *gin.Context) {
+		if _, ok := a.public[c.Request.Method+" "+c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+		identity, err := a.authenticate(c.Request.Context(), c.GetHeader(a.header))
+		if err != nil {
+			uranuserrors.HttpAbort(c, rejection(err))
+			return
+		}
+		c.Request = c.Request.WithContext(ContextWithIdentity(c.Request.Context(), identity))
+		c.Next()
+	}
+}
@@ -0,0 +1,161 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	uranuserrors "github.com/tqhuy-dev/xgen-uranus/errors"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testMethod       = "/invoice.InvoiceService/GetInvoice"
+	testPublicMethod = "/invoice.InvoiceService/Ping"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// failingStore fails every lookup.
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) (*Key, error) {
+	return nil, errors.New("database down")
+}
+
+type keyCase struct {
+	name       string
+	store      Store
+	key        string
+	wantCode   codes.Code
+	wantStatus int
+	wantReason string
+}
+
+func keyCases() []keyCase {
+	past := time.Now().Add(-time.Hour)
+	store := NewMemoryStore(
+		Key{ID: "valid", Hash: Hash("s3cret"), Owner: "billing", Scopes: []string{"invoices:read"}},
+		Key{ID: "expired", Hash: Hash("s3cret"), Owner: "billing", ExpiresAt: &past},
+		Key{ID: "disabled", Hash: Hash("s3cret"), Owner: "billing", Disabled: true},
+	)
+	return []keyCase{
+		{name: "valid key", store: store, key: "valid.s3cret", wantCode: codes.OK, wantStatus: http.StatusOK},
+		{name: "missing key", store: store, wantCode: codes.Unauthenticated, wantStatus: http.StatusUnauthorized, wantReason: InvalidKeyReason},
+		{name: "malformed key", store: store, key: "valid", wantCode: codes.Unauthenticated, wantStatus: http.StatusUnauthorized, wantReason: InvalidKeyReason},
+		{name: "unknown key", store: store, key: "other.s3cret", wantCode: codes.Unauthenticated, wantStatus: http.StatusUnauthorized, wantReason: InvalidKeyReason},
+		{name: "wrong secret", store: store, key: "valid.other", wantCode: codes.Unauthenticated, wantStatus: http.StatusUnauthorized, wantReason: InvalidKeyReason},
+		{name: "expired key", store: store, key: "expired.s3cret", wantCode: codes.Unauthenticated, wantStatus: http.StatusUnauthorized, wantReason: InvalidKeyReason},
+		{name: "disabled key", store: store, key: "disabled.s3cret", wantCode: codes.Unauthenticated, wantStatus: http.StatusUnauthorized, wantReason: InvalidKeyReason},
+		{
+			name:       "store failure",
+			store:      failingStore{},
+			key:        "valid.s3cret",
+			wantCode:   codes.Unavailable,
+			wantStatus: http.StatusServiceUnavailable,
+			wantReason: StoreUnavailableReason,
+		},
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	for _, tt := range keyCases() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(DefaultHeader, tt.key))
+			}
+			var identity *Identity
+			_, err := Authenticate(WithStore(tt.store))(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					identity, _ = FromContext(ctx)
+					if reqctx.Subject(ctx) != "billing" {
+						t.Errorf("subject = %q, want billing", reqctx.Subject(ctx))
+					}
+					return nil, nil
+				})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("code = %s, want %s (%v)", status.Code(err), tt.wantCode, err)
+			}
+			if err != nil {
+				if reason := uranuserrors.FromError(status.Convert(err).Err()).Reason; reason != tt.wantReason {
+					t.Errorf("reason = %q, want %q", reason, tt.wantReason)
+				}
+				return
+			}
+			if identity == nil || identity.KeyID != "valid" || identity.Owner != "billing" {
+				t.Errorf("identity = %+v, want the valid key of billing", identity)
+			}
+		})
+	}
+}
+
+func TestAuthenticatePublicMethods(t *testing.T) {
+	interceptor := Authenticate(WithPublicMethods(testPublicMethod))
+	for _, method := range []string{testPublicMethod, "/grpc.health.v1.Health/Check"} {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			})
+		if err != nil {
+			t.Errorf("%s: %v, want the call through without a key", method, err)
+		}
+	}
+}
+
+func TestHttpAuthenticate(t *testing.T) {
+	for _, tt := range keyCases() {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(HttpAuthenticate(WithStore(tt.store), WithHeader("X-Custom-Key"), WithPublicMethods("GET /health")))
+			var identity *Identity
+			r.GET("/invoices", func(c *gin.Context) {
+				identity, _ = FromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+			r.GET("/health", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/invoices", nil)
+			if tt.key != "" {
+				req.Header.Set("X-Custom-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				if identity == nil || identity.KeyID != "valid" {
+					t.Errorf("identity = %+v, want the valid key", identity)
+				}
+			} else {
+				var body uranuserrors.HttpBody
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("body %s: %v", w.Body.String(), err)
+				}
+				if body.Code != int32(tt.wantStatus) || body.Reason != tt.wantReason {
+					t.Errorf("body = %+v, want a %d %s error", body, tt.wantStatus, tt.wantReason)
+				}
+			}
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("public route status = %d, want 200", w.Code)
+			}
+		})
+	}
+}
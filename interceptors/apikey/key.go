@@ -0,0 +1,74 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+const hashPrefix = "sha256:"
+
+var errMalformedKey = errors.New("malformed api key")
+
+// Key is a stored API key. Only the hash of its secret is kept, the plaintext key
+// "<id>.<secret>" is shown once when minted.
+type Key struct {
+	ID        string     `yaml:"id" json:"id"`
+	Hash      string     `yaml:"hash" json:"hash"`
+	Owner     string     `yaml:"owner" json:"owner"`
+	Scopes    []string   `yaml:"scopes" json:"scopes"`
+	ExpiresAt *time.Time `yaml:"expires_at,omitempty" json:"expires_at,omitempty"`
+	Disabled  bool       `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// Mint generates a new key for owner. The returned plaintext must be handed to the caller,
+// the Key is what goes in the store.
+func Mint(owner string, scopes []string, ttl time.Duration) (string, Key) {
+	id := randomString(8)
+	secret := randomString(32)
+	key := Key{
+		ID:     id,
+		Hash:   Hash(secret),
+		Owner:  owner,
+		Scopes: scopes,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl).UTC().Truncate(time.Second)
+		key.ExpiresAt = &expiresAt
+	}
+	return id + "." + secret, key
+}
+
+// Hash returns the stored form of a secret. Secrets are random, so a fast hash is enough.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// ParseKey splits a plaintext key into its ID and secret.
+func ParseKey(plaintext string) (string, string, error) {
+	id, secret, found := strings.Cut(strings.TrimSpace(plaintext), ".")
+	if !found || id == "" || secret == "" {
+		return "", "", errMalformedKey
+	}
+	return id, secret, nil
+}
+
+// Verify reports whether secret matches the key and the key is usable.
+func (k *Key) Verify(secret string, now time.Time) bool {
+	if k.Disabled || (k.ExpiresAt != nil && now.After(*k.ExpiresAt)) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(Hash(secret)), []byte(k.Hash)) == 1
+}
+
+func randomString(size int) string {
+	b := make([]byte, size)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,89 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		plaintext  string
+		wantId     string
+		wantSecret string
+		wantErr    bool
+	}{
+		{name: "valid", plaintext: "abc.s3cret", wantId: "abc", wantSecret: "s3cret"},
+		{name: "surrounding spaces", plaintext: " abc.s3cret\n", wantId: "abc", wantSecret: "s3cret"},
+		{name: "dot in secret", plaintext: "abc.s3.cret", wantId: "abc", wantSecret: "s3.cret"},
+		{name: "empty", plaintext: "", wantErr: true},
+		{name: "no separator", plaintext: "abcs3cret", wantErr: true},
+		{name: "empty id", plaintext: ".s3cret", wantErr: true},
+		{name: "empty secret", plaintext: "abc.", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, secret, err := ParseKey(tt.plaintext)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if id != tt.wantId || secret != tt.wantSecret {
+				t.Errorf("ParseKey(%q) = %q, %q, want %q, %q", tt.plaintext, id, secret, tt.wantId, tt.wantSecret)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	past, future := now.Add(-time.Second), now.Add(time.Hour)
+	tests := []struct {
+		name   string
+		key    Key
+		secret string
+		want   bool
+	}{
+		{name: "valid", key: Key{Hash: Hash("s3cret")}, secret: "s3cret", want: true},
+		{name: "not expired", key: Key{Hash: Hash("s3cret"), ExpiresAt: &future}, secret: "s3cret", want: true},
+		{name: "wrong secret", key: Key{Hash: Hash("s3cret")}, secret: "other", want: false},
+		{name: "expired", key: Key{Hash: Hash("s3cret"), ExpiresAt: &past}, secret: "s3cret", want: false},
+		{name: "disabled", key: Key{Hash: Hash("s3cret"), Disabled: true}, secret: "s3cret", want: false},
+		{name: "plaintext stored as hash", key: Key{Hash: "s3cret"}, secret: "s3cret", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.Verify(tt.secret, now); got != tt.want {
+				t.Errorf("Verify = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMint(t *testing.T) {
+	plaintext, key := Mint("billing", []string{"invoices:read"}, time.Hour)
+	id, secret, err := ParseKey(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != key.ID || key.Owner != "billing" || len(key.Scopes) != 1 || key.Scopes[0] != "invoices:read" {
+		t.Errorf("key = %+v, want the ID %q of the plaintext, owner billing and scope invoices:read", key, id)
+	}
+	if strings.Contains(key.Hash, secret) || !strings.HasPrefix(key.Hash, hashPrefix) {
+		t.Errorf("hash = %q, want the hash of the secret", key.Hash)
+	}
+	if !key.Verify(secret, time.Now()) {
+		t.Error("minted key does not verify its secret")
+	}
+	if key.ExpiresAt == nil || key.Verify(secret, time.Now().Add(2*time.Hour)) {
+		t.Errorf("expires at = %v, want an expiry in an hour", key.ExpiresAt)
+	}
+
+	other, _ := Mint("billing", nil, 0)
+	if other == plaintext {
+		t.Error("minted the same key twice")
+	}
+	if _, key := Mint("billing", nil, 0); key.ExpiresAt != nil {
+		t.Error("key without ttl expires")
+	}
+}
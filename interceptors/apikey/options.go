@@ -0,0 +1,63 @@
+package apikey
+
+const DefaultHeader = "x-api-key"
+
+var (
+	defaultOptions = &options{
+		header: DefaultHeader,
+		public: map[string]struct{}{
+			"/grpc.health.v1.Health/Check":                                   {},
+			"/grpc.health.v1.Health/Watch":                                   {},
+			"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      {},
+			"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": {},
+		},
+	}
+)
+
+type options struct {
+	store  Store
+	header string
+	public map[string]struct{}
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	optCopy.public = make(map[string]struct{}, len(defaultOptions.public))
+	for method := range defaultOptions.public {
+		optCopy.public[method] = struct{}{}
+	}
+	for _, o := range opts {
+		o(optCopy)
+	}
+	if optCopy.store == nil {
+		optCopy.store = NewMemoryStore()
+	}
+	return optCopy
+}
+
+type Option func(*options)
+
+// WithStore verifies keys against store, e.g. NewFileStore("keys.yaml").
+func WithStore(store Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// WithHeader reads the key from another metadata key or header than "x-api-key".
+func WithHeader(header string) Option {
+	return func(o *options) {
+		o.header = header
+	}
+}
+
+// WithPublicMethods lets the given gRPC methods or gin routes ("GET /health") through without a key.
+// Health checks and reflection are public by default.
+func WithPublicMethods(methods ...string) Option {
+	return func(o *options) {
+		for _, method := range methods {
+			o.public[method] = struct{}{}
+		}
+	}
+}
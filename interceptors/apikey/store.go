@@ -0,0 +1,145 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ErrKeyNotFound is returned by a Store that has no key with the requested ID.
+var ErrKeyNotFound = errors.New("api key not found")
+
+// Store looks keys up by ID.
+type Store interface {
+	Get(ctx context.Context, id string) (*Key, error)
+}
+
+// MemoryStore keeps keys in memory.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys map[string]Key
+}
+
+func NewMemoryStore(keys ...Key) *MemoryStore {
+	s := &MemoryStore{keys: make(map[string]Key, len(keys))}
+	for _, key := range keys {
+		s.keys[key.ID] = key
+	}
+	return s
+}
+
+func (s *MemoryStore) Add(key Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key.ID] = key
+}
+
+func (s *MemoryStore) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, id)
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return &key, nil
+}
+
+// KeyFile is the content of a key file, in YAML or JSON.
+type KeyFile struct {
+	Keys []Key `yaml:"keys" json:"keys"`
+}
+
+// LoadKeyFile reads a key file, a missing file has no keys.
+func LoadKeyFile(path string) (*KeyFile, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &KeyFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+	file := &KeyFile{}
+	if err := yaml.Unmarshal(content, file); err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// SaveKeyFile writes a key file as YAML, readable by its owner only.
+func SaveKeyFile(path string, file *KeyFile) error {
+	content, err := yaml.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode key file %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write key file %s: %w", path, err)
+	}
+	return nil
+}
+
+// fileCheckInterval bounds how often a FileStore checks whether its file changed.
+const fileCheckInterval = 5 * time.Second
+
+// FileStore serves the keys of a key file and reloads it when it changes, so that keys can be
+// added or revoked without a restart.
+type FileStore struct {
+	path      string
+	mu        sync.Mutex
+	keys      *MemoryStore
+	modTime   time.Time
+	lastCheck time.Time
+	now       func() time.Time
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, now: time.Now}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStore) Get(ctx context.Context, id string) (*Key, error) {
+	s.mu.Lock()
+	if s.now().Sub(s.lastCheck) > fileCheckInterval {
+		// on error keep serving the previous keys, the file may be in the middle of an edit
+		_ = s.reload()
+	}
+	keys := s.keys
+	s.mu.Unlock()
+	return keys.Get(ctx, id)
+}
+
+// reload must be called with mu held or before the store is shared.
+func (s *FileStore) reload() error {
+	s.lastCheck = s.now()
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat key file %s: %w", s.path, err)
+	}
+	if s.keys != nil && info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+	file, err := LoadKeyFile(s.path)
+	if err != nil {
+		return err
+	}
+	s.keys = NewMemoryStore(file.Keys...)
+	s.modTime = info.ModTime()
+	return nil
+}
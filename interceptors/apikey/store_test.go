@@ -0,0 +1,95 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeKeyFile(t *testing.T, path string, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// set the modification time explicitly, writes within the same tick keep it
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	modTime := time.Unix(1_700_000_000, 0)
+	writeKeyFile(t, path, "keys:\n  - id: a\n    hash: "+Hash("one")+"\n    owner: alice\n", modTime)
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := store.lastCheck
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+	if key, err := store.Get(ctx, "a"); err != nil || key.Owner != "alice" {
+		t.Fatalf("key a = %+v, %v", key, err)
+	}
+
+	// a is revoked and b added
+	writeKeyFile(t, path, "keys:\n  - id: b\n    hash: "+Hash("two")+"\n    owner: bob\n", modTime.Add(time.Minute))
+	if _, err := store.Get(ctx, "b"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("key b found before the check interval: %v", err)
+	}
+	now = now.Add(fileCheckInterval + time.Second)
+	if key, err := store.Get(ctx, "b"); err != nil || key.Owner != "bob" {
+		t.Errorf("key b = %+v, %v, want the added key", key, err)
+	}
+	if _, err := store.Get(ctx, "a"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("revoked key a: %v, want ErrKeyNotFound", err)
+	}
+
+	// a broken edit keeps the previous keys
+	writeKeyFile(t, path, "keys: [", modTime.Add(2*time.Minute))
+	now = now.Add(fileCheckInterval + time.Second)
+	if key, err := store.Get(ctx, "b"); err != nil || key.Owner != "bob" {
+		t.Errorf("key b after a parse error = %+v, %v, want the previous keys", key, err)
+	}
+}
+
+func TestNewFileStore(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewFileStore(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("store created for a missing file")
+	}
+	path := filepath.Join(dir, "broken.yaml")
+	writeKeyFile(t, path, "keys: [", time.Now())
+	if _, err := NewFileStore(path); err == nil {
+		t.Error("store created for a broken file")
+	}
+}
+
+func TestKeyFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "keys.yaml")
+	expiresAt := time.Unix(1_700_000_000, 0).UTC()
+	want := &KeyFile{Keys: []Key{{ID: "a", Hash: Hash("one"), Owner: "alice", Scopes: []string{"read"}, ExpiresAt: &expiresAt, Disabled: true}}}
+	if err := SaveKeyFile(path, want); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("key file mode = %v, %v, want 600", info, err)
+	}
+	got, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := got.Keys[0]
+	if len(got.Keys) != 1 || key.ID != "a" || key.Owner != "alice" || !key.Disabled || !key.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("keys = %+v, want %+v", got.Keys, want.Keys)
+	}
+
+	missing, err := LoadKeyFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil || len(missing.Keys) != 0 {
+		t.Errorf("missing file = %+v, %v, want no keys", missing, err)
+	}
+}
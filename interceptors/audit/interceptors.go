@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newEvent returns the event of a request handled with ctx, the actor, tenant and correlation ID
+// are read from its reqctx.RequestMeta.
+func (o *options) newEvent(ctx context.Context, method string, start time.Time) Event {
+	meta, _ := reqctx.FromContext(ctx)
+	return Event{
+		Time:          start.UTC(),
+		Actor:         meta.Subject,
+		Tenant:        meta.Tenant,
+		Method:        method,
+		CorrelationId: reqctx.CorrelationId(ctx),
+		ClientIP:      meta.ClientIP,
+		DurationMs:    float64(time.Since(start).Microseconds()) / 1000,
+		AppName:       o.appName,
+	}
+}
+
+// write hands event to every sink. A failing sink does not fail the request, it is logged instead.
+func (o *options) write(ctx context.Context, event Event) {
+	ctx = context.WithoutCancel(ctx)
+	for _, sink := range o.sinks {
+		if err := sink.Write(ctx, event); err != nil {
+			o.zapLog.Error("failed to write audit event",
+				zap.String(common.LogKeyAppName, o.appName),
+				zap.String("method", event.Method),
+				zap.String("resource_id", event.ResourceId),
+				zap.String("actor", event.Actor),
+				zap.String("correlation_id", event.CorrelationId),
+				zap.Error(err))
+		}
+	}
+}
+
+// Audit returns a new unary server interceptor recording the calls to the audited methods: the ones
+// declared with the (audit.audited) method option and the ones given to WithMethods.
+// Place it after the authentication and tenant interceptors so that the events carry the actor and tenant.
+func Audit(opts ...Option) grpc.UnaryServerInterceptor {
+	o := evaluateOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		mo := lookupMethodOptions(info.FullMethod)
+		if _, ok := o.methods[info.FullMethod]; !ok && !mo.audited {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		event := o.newEvent(ctx, info.FullMethod, start)
+		field := mo.resourceField
+		if field == "" {
+			field = o.resourceField(info.FullMethod)
+		}
+		// created resources only have an ID in the response
+		if event.ResourceId = resourceId(req, field); event.ResourceId == "" && err == nil {
+			event.ResourceId = resourceId(resp, field)
+		}
+		code := status.Code(err)
+		event.Code = code.String()
+		event.Outcome = OutcomeSuccess
+		if code != codes.OK {
+			event.Outcome = OutcomeFailure
+			event.Error = status.Convert(err).Message()
+		}
+		o.write(ctx, event)
+		return resp, err
+	}
+}
+
+// HttpAudit is a Gin middleware recording the requests to the audited routes, see WithMethods and
+// WithHttpMutations. The resource ID is read from the "id" route parameter by default.
+func HttpAudit(opts ...Option) gin.HandlerFunc {
+	o := evaluateOptions(opts)
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if !o.httpAudited(c.Request.Method, route) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+
+		c.Next()
+
+		method := c.Request.Method + " " + route
+		ctx := c.Request.Context()
+		event := o.newEvent(ctx, method, start)
+		event.ResourceId = c.Param(o.resourceField(method))
+		if event.ClientIP == "" {
+			event.ClientIP = c.ClientIP()
+		}
+		statusCode := c.Writer.Status()
+		event.Code = strconv.Itoa(statusCode)
+		event.Outcome = OutcomeSuccess
+		if statusCode >= 400 || len(c.Errors) > 0 {
+			event.Outcome = OutcomeFailure
+			if last := c.Errors.Last(); last != nil {
+				event.Error = last.Error()
+			}
+		}
+		o.write(ctx, event)
+	}
+}
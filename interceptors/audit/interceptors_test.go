@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// memorySink keeps the events in memory.
+type memorySink struct {
+	mu     sync.Mutex
+	events []Event
+	err    error
+}
+
+func (s *memorySink) Write(_ context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func TestHttpAudited(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		method string
+		route  string
+		want   bool
+	}{
+		{name: "nothing audited by default", method: http.MethodPost, route: "/orders", want: false},
+		{name: "mutation", opts: []Option{WithHttpMutations()}, method: http.MethodPost, route: "/orders", want: true},
+		{name: "put", opts: []Option{WithHttpMutations()}, method: http.MethodPut, route: "/orders/:id", want: true},
+		{name: "patch", opts: []Option{WithHttpMutations()}, method: http.MethodPatch, route: "/orders/:id", want: true},
+		{name: "delete", opts: []Option{WithHttpMutations()}, method: http.MethodDelete, route: "/orders/:id", want: true},
+		{name: "read", opts: []Option{WithHttpMutations()}, method: http.MethodGet, route: "/orders", want: false},
+		{name: "unmatched route", opts: []Option{WithHttpMutations()}, method: http.MethodPost, route: "", want: false},
+		{name: "listed route", opts: []Option{WithMethods("GET /orders/:id")}, method: http.MethodGet, route: "/orders/:id", want: true},
+		{name: "other method of listed route", opts: []Option{WithMethods("GET /orders/:id")}, method: http.MethodDelete, route: "/orders/:id", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluateOptions(tt.opts).httpAudited(tt.method, tt.route); got != tt.want {
+				t.Errorf("httpAudited(%s %s) = %t, want %t", tt.method, tt.route, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHttpAudit(t *testing.T) {
+	sink := &memorySink{}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(reqctx.WithMeta(c.Request.Context(), reqctx.RequestMeta{
+			Subject: "alice", Tenant: "acme", CorrelationId: "corr-1",
+		}))
+	}, HttpAudit(WithSinks(sink), WithHttpMutations(), WithZapLog(zap.NewNop())))
+	r.DELETE("/orders/:id", func(c *gin.Context) {
+		if c.Param("id") == "locked" {
+			_ = c.Error(errors.New("order locked"))
+			c.Status(http.StatusConflict)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/orders/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, target := range []string{"/orders/42", "/orders/locked", "/orders/43"} {
+		method := http.MethodDelete
+		if target == "/orders/43" {
+			method = http.MethodGet
+		}
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, nil))
+	}
+
+	if len(sink.events) != 2 {
+		t.Fatalf("events = %d, want 2", len(sink.events))
+	}
+	success, failure := sink.events[0], sink.events[1]
+	if success.Actor != "alice" || success.Tenant != "acme" || success.CorrelationId != "corr-1" ||
+		success.Method != "DELETE /orders/:id" || success.ResourceId != "42" ||
+		success.Outcome != OutcomeSuccess || success.Code != "204" {
+		t.Errorf("success event = %+v", success)
+	}
+	if failure.ResourceId != "locked" || failure.Outcome != OutcomeFailure || failure.Code != "409" || failure.Error != "order locked" {
+		t.Errorf("failure event = %+v", failure)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	const method = "/order.OrderService/CreateOrder"
+	tests := []struct {
+		name         string
+		opts         []Option
+		method       string
+		req          interface{}
+		resp         interface{}
+		err          error
+		wantEvent    bool
+		wantResource string
+		wantOutcome  string
+		wantCode     string
+	}{
+		{name: "unaudited method", method: "/order.OrderService/GetOrder", req: wrapperspb.String("1")},
+		{
+			name:         "resource from the request",
+			opts:         []Option{WithResourceField(method, "value")},
+			method:       method,
+			req:          wrapperspb.String("42"),
+			resp:         wrapperspb.String("ignored"),
+			wantEvent:    true,
+			wantResource: "42",
+			wantOutcome:  OutcomeSuccess,
+			wantCode:     "OK",
+		},
+		{
+			name:         "resource from the response",
+			opts:         []Option{WithResourceField(method, "value")},
+			method:       method,
+			req:          wrapperspb.String(""),
+			resp:         wrapperspb.String("43"),
+			wantEvent:    true,
+			wantResource: "43",
+			wantOutcome:  OutcomeSuccess,
+			wantCode:     "OK",
+		},
+		{
+			name:        "failure",
+			opts:        []Option{WithResourceField(method, "value")},
+			method:      method,
+			req:         wrapperspb.String(""),
+			err:         status.Error(codes.PermissionDenied, "denied"),
+			wantEvent:   true,
+			wantOutcome: OutcomeFailure,
+			wantCode:    "PermissionDenied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &memorySink{}
+			opts := append([]Option{WithSinks(sink), WithMethods(method), WithZapLog(zap.NewNop())}, tt.opts...)
+			_, err := Audit(opts...)(context.Background(), tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return tt.resp, tt.err
+				})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if !tt.wantEvent {
+				if len(sink.events) != 0 {
+					t.Errorf("events = %+v, want none", sink.events)
+				}
+				return
+			}
+			if len(sink.events) != 1 {
+				t.Fatalf("events = %d, want 1", len(sink.events))
+			}
+			event := sink.events[0]
+			if event.ResourceId != tt.wantResource || event.Outcome != tt.wantOutcome || event.Code != tt.wantCode {
+				t.Errorf("event = %+v, want resource %q, outcome %s, code %s", event, tt.wantResource, tt.wantOutcome, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAuditSinkFailureDoesNotFailCall(t *testing.T) {
+	sink := &memorySink{err: errors.New("disk full")}
+	resp, err := Audit(WithSinks(sink), WithMethods("/order.OrderService/DeleteOrder"), WithZapLog(zap.NewNop()))(
+		context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/order.OrderService/DeleteOrder"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "deleted", nil
+		})
+	if err != nil || resp != "deleted" || len(sink.events) != 1 {
+		t.Errorf("response = %v, %v with %d events, want the handler response and 1 event", resp, err, len(sink.events))
+	}
+}
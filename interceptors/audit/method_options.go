@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Field numbers of the options declared in grpc_third_party/audit/audit.proto.
+const (
+	auditedField       protowire.Number = 1110
+	resourceFieldField protowire.Number = 1111
+)
+
+// methodOptions are the audit options of a gRPC method.
+type methodOptions struct {
+	audited       bool
+	resourceField string
+}
+
+// methodOptionsCache caches the options by full method, the descriptors do not change at runtime.
+var methodOptionsCache sync.Map
+
+// lookupMethodOptions returns the audit options of a full method ("/pkg.Service/Method"), read from
+// the method descriptor registered by the generated code.
+func lookupMethodOptions(fullMethod string) methodOptions {
+	if cached, ok := methodOptionsCache.Load(fullMethod); ok {
+		return cached.(methodOptions)
+	}
+	var mo methodOptions
+	name := strings.ReplaceAll(strings.TrimPrefix(fullMethod, "/"), "/", ".")
+	if descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name)); err == nil {
+		if method, ok := descriptor.(protoreflect.MethodDescriptor); ok {
+			mo = parseMethodOptions(method.Options())
+		}
+	}
+	methodOptionsCache.Store(fullMethod, mo)
+	return mo
+}
+
+// parseMethodOptions scans the options in their wire format so that it works whether the audit.proto
+// extensions are registered in this binary or not.
+func parseMethodOptions(options proto.Message) methodOptions {
+	var mo methodOptions
+	if options == nil {
+		return mo
+	}
+	b, err := proto.Marshal(options)
+	if err != nil {
+		return mo
+	}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return mo
+		}
+		b = b[n:]
+		switch {
+		case num == auditedField && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return mo
+			}
+			mo.audited = v != 0
+			b = b[n:]
+		case num == resourceFieldField && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return mo
+			}
+			mo.resourceField = string(v)
+			b = b[n:]
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return mo
+			}
+			b = b[n:]
+		}
+	}
+	return mo
+}
+
+// resourceId returns the scalar value of a field of msg, dotted for nested messages.
+func resourceId(msg interface{}, field string) string {
+	m, ok := msg.(proto.Message)
+	if !ok || m == nil || field == "" {
+		return ""
+	}
+	reflected := m.ProtoReflect()
+	if !reflected.IsValid() {
+		return ""
+	}
+	names := strings.Split(field, ".")
+	for i, name := range names {
+		fd := reflected.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.IsList() || fd.IsMap() || !reflected.Has(fd) {
+			return ""
+		}
+		value := reflected.Get(fd)
+		if i == len(names)-1 {
+			if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+				return ""
+			}
+			return value.String()
+		}
+		if fd.Kind() != protoreflect.MessageKind {
+			return ""
+		}
+		reflected = value.Message()
+	}
+	return ""
+}
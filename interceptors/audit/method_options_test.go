@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// methodOptionsWire returns method options carrying the given raw extension fields.
+func methodOptionsWire(fields ...func(b []byte) []byte) *descriptorpb.MethodOptions {
+	var b []byte
+	for _, field := range fields {
+		b = field(b)
+	}
+	options := &descriptorpb.MethodOptions{}
+	options.ProtoReflect().SetUnknown(b)
+	return options
+}
+
+func audited(value uint64) func(b []byte) []byte {
+	return func(b []byte) []byte {
+		b = protowire.AppendTag(b, auditedField, protowire.VarintType)
+		return protowire.AppendVarint(b, value)
+	}
+}
+
+func resourceFieldOption(value string) func(b []byte) []byte {
+	return func(b []byte) []byte {
+		b = protowire.AppendTag(b, resourceFieldField, protowire.BytesType)
+		return protowire.AppendString(b, value)
+	}
+}
+
+func TestParseMethodOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *descriptorpb.MethodOptions
+		want    methodOptions
+	}{
+		{name: "nil options", want: methodOptions{}},
+		{name: "no audit options", options: methodOptionsWire(), want: methodOptions{}},
+		{name: "audited", options: methodOptionsWire(audited(1)), want: methodOptions{audited: true}},
+		{name: "audited false", options: methodOptionsWire(audited(0)), want: methodOptions{}},
+		{
+			name:    "audited with resource field",
+			options: methodOptionsWire(audited(1), resourceFieldOption("order.id")),
+			want:    methodOptions{audited: true, resourceField: "order.id"},
+		},
+		{
+			name:    "last occurrence wins",
+			options: methodOptionsWire(audited(1), audited(0), resourceFieldOption("a"), resourceFieldOption("b")),
+			want:    methodOptions{resourceField: "b"},
+		},
+		{
+			name: "other extensions skipped",
+			options: methodOptionsWire(func(b []byte) []byte {
+				b = protowire.AppendTag(b, 50000, protowire.BytesType)
+				return protowire.AppendString(b, "other")
+			}, audited(1)),
+			want: methodOptions{audited: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got methodOptions
+			if tt.options == nil {
+				got = parseMethodOptions(nil)
+			} else {
+				got = parseMethodOptions(tt.options)
+			}
+			if got != tt.want {
+				t.Errorf("options = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceId(t *testing.T) {
+	name := "orders.proto"
+	goPackage := "example.com/orders"
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       &name,
+		Dependency: []string{"a.proto"},
+		Options:    &descriptorpb.FileOptions{GoPackage: &goPackage},
+	}
+	tests := []struct {
+		name  string
+		msg   interface{}
+		field string
+		want  string
+	}{
+		{name: "top-level field", msg: file, field: "name", want: "orders.proto"},
+		{name: "nested field", msg: file, field: "options.go_package", want: "example.com/orders"},
+		{name: "numeric field", msg: wrapperspb.Int64(42), field: "value", want: "42"},
+		{name: "unset nested field", msg: file, field: "options.java_package", want: ""},
+		{name: "unset message", msg: file, field: "source_code_info.location", want: ""},
+		{name: "unknown field", msg: file, field: "id", want: ""},
+		{name: "message field", msg: file, field: "options", want: ""},
+		{name: "list field", msg: file, field: "dependency", want: ""},
+		{name: "through a scalar", msg: file, field: "name.length", want: ""},
+		{name: "empty field", msg: file, field: "", want: ""},
+		{name: "not a proto message", msg: "orders", field: "name", want: ""},
+		{name: "nil message", msg: (*descriptorpb.FileDescriptorProto)(nil), field: "name", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resourceId(tt.msg, tt.field); got != tt.want {
+				t.Errorf("resourceId(%q) = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// DefaultResourceField is the request field, response field or route parameter holding the resource ID.
+const DefaultResourceField = "id"
+
+var (
+	defaultOptions = &options{}
+)
+
+type options struct {
+	sinks          []Sink
+	methods        map[string]struct{}
+	resourceFields map[string]string
+	httpMutations  bool
+	zapLog         *zap.Logger
+	appName        string
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	optCopy.methods = make(map[string]struct{})
+	optCopy.resourceFields = make(map[string]string)
+	for _, o := range opts {
+		o(optCopy)
+	}
+	if optCopy.zapLog == nil {
+		optCopy.zapLog, _ = zap.NewProduction()
+	}
+	return optCopy
+}
+
+// httpAudited reports whether the gin route of a request with method is audited.
+func (o *options) httpAudited(method string, route string) bool {
+	if _, ok := o.methods[method+" "+route]; ok {
+		return true
+	}
+	if !o.httpMutations || route == "" {
+		return false
+	}
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// resourceField returns the field holding the resource ID of method.
+func (o *options) resourceField(method string) string {
+	if field, ok := o.resourceFields[method]; ok {
+		return field
+	}
+	return DefaultResourceField
+}
+
+type Option func(*options)
+
+// WithSinks writes the events to sinks.
+func WithSinks(sinks ...Sink) Option {
+	return func(o *options) {
+		o.sinks = append(o.sinks, sinks...)
+	}
+}
+
+// WithMethods audits the given gRPC methods or gin routes ("DELETE /orders/:id"), in addition
+// to the gRPC methods declared with the (audit.audited) option.
+func WithMethods(methods ...string) Option {
+	return func(o *options) {
+		for _, method := range methods {
+			o.methods[method] = struct{}{}
+		}
+	}
+}
+
+// WithHttpMutations audits every POST, PUT, PATCH and DELETE gin route.
+func WithHttpMutations() Option {
+	return func(o *options) {
+		o.httpMutations = true
+	}
+}
+
+// WithResourceField reads the resource ID of method from field: a request or response field for gRPC
+// (dotted for nested messages, "order.id"), a route parameter for gin. DefaultResourceField by default,
+// the (audit.resource_field) option of gRPC methods takes precedence.
+func WithResourceField(method string, field string) Option {
+	return func(o *options) {
+		o.resourceFields[method] = field
+	}
+}
+
+// WithZapLog sets the logger reporting the events that could not be written.
+func WithZapLog(zapLog *zap.Logger) Option {
+	return func(o *options) {
+		o.zapLog = zapLog
+	}
+}
+
+func WithAppName(appName string) Option {
+	return func(o *options) {
+		o.appName = appName
+	}
+}
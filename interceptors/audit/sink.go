@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tqhuy-dev/xgen-uranus/kafka_provider"
+)
+
+// Outcomes of audited operations.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event records an audited operation.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Actor is the authenticated subject, empty for anonymous calls.
+	Actor  string `json:"actor"`
+	Tenant string `json:"tenant,omitempty"`
+	// Method is the full gRPC method ("/pkg.Service/Method") or the gin route ("DELETE /orders/:id").
+	Method     string `json:"method"`
+	ResourceId string `json:"resource_id,omitempty"`
+	Outcome    string `json:"outcome"`
+	// Code is the gRPC status code or the HTTP status of the response.
+	Code          string  `json:"code"`
+	Error         string  `json:"error,omitempty"`
+	DurationMs    float64 `json:"duration_ms"`
+	CorrelationId string  `json:"correlation_id,omitempty"`
+	ClientIP      string  `json:"client_ip,omitempty"`
+	AppName       string  `json:"app_name,omitempty"`
+}
+
+// Sink stores the audit events. Write is called synchronously once the audited request is handled,
+// implementations should not block for long.
+type Sink interface {
+	Write(ctx context.Context, event Event) error
+}
+
+// FileSink appends the events to a file, one JSON document per line. The file is only ever appended to.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens or creates the audit file at path.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file %s: %w", path, err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Write(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the audit file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// contextProducer is implemented by kafka_provider.AsyncProducer.
+type contextProducer interface {
+	SendMessagesWithContext(ctx context.Context, topic string, key string, data []byte) error
+}
+
+// SyncProducer sends a message and waits for its acknowledgement, it is implemented by
+// *kafka_provider.SyncProducer.
+type SyncProducer interface {
+	SendMessagesWithContext(ctx context.Context, topic string, key string, data []byte) (int32, int64, error)
+}
+
+// KafkaSink publishes the events to a topic as JSON, keyed by resource ID so that the events of
+// a resource keep their order.
+type KafkaSink struct {
+	producer     kafka_provider.IProducer
+	syncProducer SyncProducer
+	topic        string
+}
+
+// NewKafkaSink publishes the events to topic with producer, a kafka_provider.AsyncProducer for instance.
+// Asynchronous producers only enqueue the events, their delivery failures are not returned by Write:
+// use NewSyncKafkaSink when no event may be lost silently.
+func NewKafkaSink(producer kafka_provider.IProducer, topic string) *KafkaSink {
+	return &KafkaSink{producer: producer, topic: topic}
+}
+
+// NewSyncKafkaSink publishes the events to topic with producer, a *kafka_provider.SyncProducer for
+// instance. Write returns once the event is acknowledged, or with the error of its delivery.
+func NewSyncKafkaSink(producer SyncProducer, topic string) *KafkaSink {
+	return &KafkaSink{syncProducer: producer, topic: topic}
+}
+
+func (s *KafkaSink) Write(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	key := event.ResourceId
+	if key == "" {
+		key = event.Method
+	}
+	// the producers of kafka_provider also propagate the trace, correlation ID and tenant
+	if s.syncProducer != nil {
+		_, _, err = s.syncProducer.SendMessagesWithContext(ctx, s.topic, key, data)
+		return err
+	}
+	if producer, ok := s.producer.(contextProducer); ok {
+		return producer.SendMessagesWithContext(ctx, s.topic, key, data)
+	}
+	return s.producer.SendMessages(s.topic, key, data)
+}
@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tqhuy-dev/xgen-uranus/kafka_provider"
+)
+
+var _ SyncProducer = (*kafka_provider.SyncProducer)(nil)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	events := []Event{
+		{Time: time.Unix(1_700_000_000, 0).UTC(), Actor: "alice", Method: "/order.OrderService/DeleteOrder", ResourceId: "42", Outcome: OutcomeSuccess, Code: "OK"},
+		{Time: time.Unix(1_700_000_001, 0).UTC(), Actor: "bob", Method: "DELETE /orders/:id", ResourceId: "43", Outcome: OutcomeFailure, Code: "403"},
+	}
+
+	// the second sink appends to the file of the first one
+	for _, event := range events {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Write(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("file mode = %o, want 600", mode)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var got []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, event)
+	}
+	if len(got) != len(events) {
+		t.Fatalf("lines = %d, want %d", len(got), len(events))
+	}
+	for i := range events {
+		if got[i] != events[i] {
+			t.Errorf("line %d = %+v, want %+v", i+1, got[i], events[i])
+		}
+	}
+}
+
+type sentMessage struct {
+	topic string
+	key   string
+	event Event
+}
+
+type fakeSyncProducer struct {
+	sent []sentMessage
+	err  error
+}
+
+func (p *fakeSyncProducer) SendMessagesWithContext(ctx context.Context, topic string, key string, data []byte) (int32, int64, error) {
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return 0, 0, err
+	}
+	p.sent = append(p.sent, sentMessage{topic: topic, key: key, event: event})
+	return 0, int64(len(p.sent)), p.err
+}
+
+type fakeAsyncProducer struct {
+	sent []sentMessage
+}
+
+func (p *fakeAsyncProducer) SendMessages(topic string, key string, data []byte) error {
+	p.sent = append(p.sent, sentMessage{topic: topic, key: key})
+	return nil
+}
+
+func TestSyncKafkaSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   Event
+		err     error
+		wantKey string
+	}{
+		{name: "keyed by resource", event: Event{Method: "/order.OrderService/DeleteOrder", ResourceId: "42"}, wantKey: "42"},
+		{name: "keyed by method without resource", event: Event{Method: "/order.OrderService/DeleteOrder"}, wantKey: "/order.OrderService/DeleteOrder"},
+		{name: "delivery failure returned", event: Event{ResourceId: "42"}, err: errors.New("broker down"), wantKey: "42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := &fakeSyncProducer{err: tt.err}
+			err := NewSyncKafkaSink(producer, "audit").Write(context.Background(), tt.event)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+			if len(producer.sent) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(producer.sent))
+			}
+			sent := producer.sent[0]
+			if sent.topic != "audit" || sent.key != tt.wantKey || sent.event != tt.event {
+				t.Errorf("sent %+v, want %+v to audit with key %q", sent, tt.event, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	producer := &fakeAsyncProducer{}
+	if err := NewKafkaSink(producer, "audit").Write(context.Background(), Event{ResourceId: "42"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(producer.sent) != 1 || producer.sent[0].key != "42" {
+		t.Errorf("sent %+v, want one message keyed 42", producer.sent)
+	}
+}
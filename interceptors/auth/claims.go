@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+)
+
+// Claims are the validated claims of the caller's token.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Scope is the space separated list of granted scopes (RFC 8693).
+	Scope string   `json:"scope,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+	// Extra holds every claim of the token, including the ones above.
+	Extra map[string]interface{} `json:"-"`
+}
+
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	type plain Claims
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.Extra)
+}
+
+// Scopes splits Scope.
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
+
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// String returns the value of a custom string claim.
+func (c *Claims) String(name string) string {
+	value, _ := c.Extra[name].(string)
+	return value
+}
+
+type claimsKey struct{}
+
+// ContextWithClaims returns a copy of ctx carrying claims.
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	ctx = reqctx.Update(ctx, func(meta *reqctx.RequestMeta) {
+		meta.Subject = claims.Subject
+	})
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the authenticated caller.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// SubjectFromContext returns the subject of the authenticated caller, empty when anonymous.
+func SubjectFromContext(ctx context.Context) string {
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		return claims.Subject
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var errMissingToken = errors.New("missing bearer token")
+
+type authenticator struct {
+	*options
+	parser *jwt.Parser
+}
+
+func newAuthenticator(opts []Option) *authenticator {
+	o := evaluateOptions(opts)
+	if o.jwksFile != "" {
+		if err := o.keys.loadJWKS(o.jwksFile); err != nil {
+			panic(err)
+		}
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(o.validMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(o.leeway),
+	}
+	if o.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(o.issuer))
+	}
+	if o.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(o.audience))
+	}
+	return &authenticator{options: o, parser: jwt.NewParser(parserOpts...)}
+}
+
+func (a *authenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := a.keys.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// authenticate validates an "Authorization: Bearer <token>" value.
+func (a *authenticator) authenticate(authorization string) (*Claims, error) {
+	scheme, token, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return nil, errMissingToken
+	}
+	claims := &Claims{}
+	if _, err := a.parser.ParseWithClaims(strings.TrimSpace(token), claims, a.keyFunc); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (a *authenticator) authenticateGrpc(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorization := ""
+	if values := md.Get("authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+	claims, err := a.authenticate(authorization)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return ContextWithClaims(ctx, claims), nil
+}
+
+// Authenticate returns a new unary server interceptor that requires a valid bearer JWT in the
+// "authorization" metadata and puts its claims in the context, see ClaimsFromContext.
+// It panics if the JWKS file cannot be loaded.
+func Authenticate(opts ...Option) grpc.UnaryServerInterceptor {
+	a := newAuthenticator(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := a.public[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+		newCtx, err := a.authenticateGrpc(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(newCtx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new streaming server interceptor that requires a valid bearer JWT.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	a := newAuthenticator(opts)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := a.public[info.FullMethod]; ok {
+			return handler(srv, stream)
+		}
+		newCtx, err := a.authenticateGrpc(stream.Context())
+		if err != nil {
+			return err
+		}
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = newCtx
+		return handler(srv, wrapped)
+	}
+}
+
+// HttpAuthenticate is a Gin middleware that requires a valid bearer JWT in the Authorization header
+// and puts its claims in the request context, see ClaimsFromContext.
+func HttpAuthenticate(opts ...Option) gin.HandlerFunc {
+	a := newAuthenticator(opts)
+	return func(c *gin.Context) {
+		if _, ok := a.public[c.Request.Method+" "+c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+		claims, err := a.authenticate(c.GetHeader("Authorization"))
+		if err != nil {
+			// requests without a token only get the challenge (RFC 6750 section 3.1)
+			challenge := "Bearer"
+			if !errors.Is(err, errMissingToken) {
+				challenge = `Bearer error="invalid_token"`
+			}
+			c.Header("WWW-Authenticate", challenge)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Unauthenticated",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.Request = c.Request.WithContext(ContextWithClaims(c.Request.Context(), claims))
+		c.Next()
+	}
+}
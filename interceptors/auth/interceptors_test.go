@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "orders"
+	testMethod   = "/order.OrderService/GetOrder"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub": "alice",
+		"iss": testIssuer,
+		"aud": testAudience,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+// sign returns a token of claims signed by the RSA test key under kid, after applying change to claims.
+func sign(kid string, change func(claims jwt.MapClaims)) string {
+	claims := validClaims()
+	if change != nil {
+		change(claims)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(testRSAKey())
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func testOptions(t *testing.T) []Option {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(rsaJWK("rsa-1", &testRSAKey().PublicKey)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return []Option{WithJWKSFile(path), WithIssuer(testIssuer), WithAudience(testAudience), WithPublicMethods(testPublicMethod, "GET /public")}
+}
+
+const testPublicMethod = "/order.OrderService/ListProducts"
+
+type authCase struct {
+	name     string
+	header   string
+	wantCode codes.Code
+}
+
+func authCases() []authCase {
+	hmacWithRSAKey := func() string {
+		// the public RSA key used as an HMAC secret, the classic algorithm confusion attack
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+		token.Header["kid"] = "rsa-1"
+		der, err := x509.MarshalPKIXPublicKey(&testRSAKey().PublicKey)
+		if err != nil {
+			panic(err)
+		}
+		signed, err := token.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		if err != nil {
+			panic(err)
+		}
+		return signed
+	}
+	unsigned := func() string {
+		token := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims())
+		token.Header["kid"] = "rsa-1"
+		signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		if err != nil {
+			panic(err)
+		}
+		return signed
+	}
+	return []authCase{
+		{name: "valid token", header: "Bearer " + sign("rsa-1", nil), wantCode: codes.OK},
+		{name: "lowercase scheme", header: "bearer " + sign("rsa-1", nil), wantCode: codes.OK},
+		{name: "missing token", header: "", wantCode: codes.Unauthenticated},
+		{name: "other scheme", header: "Basic YWxpY2U6c2VjcmV0", wantCode: codes.Unauthenticated},
+		{name: "malformed token", header: "Bearer not.a.token", wantCode: codes.Unauthenticated},
+		{
+			name:     "expired",
+			header:   "Bearer " + sign("rsa-1", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "without expiration",
+			header:   "Bearer " + sign("rsa-1", func(c jwt.MapClaims) { delete(c, "exp") }),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "not yet valid",
+			header:   "Bearer " + sign("rsa-1", func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(time.Hour).Unix() }),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "issuer mismatch",
+			header:   "Bearer " + sign("rsa-1", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "audience mismatch",
+			header:   "Bearer " + sign("rsa-1", func(c jwt.MapClaims) { c["aud"] = "billing" }),
+			wantCode: codes.Unauthenticated,
+		},
+		{name: "unknown kid", header: "Bearer " + sign("rsa-2", nil), wantCode: codes.Unauthenticated},
+		{name: "alg none", header: "Bearer " + unsigned(), wantCode: codes.Unauthenticated},
+		{name: "HS256 with the RSA key", header: "Bearer " + hmacWithRSAKey(), wantCode: codes.Unauthenticated},
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	interceptor := Authenticate(testOptions(t)...)
+	for _, tt := range authCases() {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.header))
+			}
+			var subject string
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				subject = SubjectFromContext(ctx)
+				return nil, nil
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("code = %s, want %s (%v)", status.Code(err), tt.wantCode, err)
+			}
+			if tt.wantCode == codes.OK && subject != "alice" {
+				t.Errorf("subject = %q, want alice", subject)
+			}
+		})
+	}
+}
+
+func TestAuthenticatePublicMethods(t *testing.T) {
+	interceptor := Authenticate(testOptions(t)...)
+	for _, method := range []string{testPublicMethod, "/grpc.health.v1.Health/Check"} {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if _, ok := ClaimsFromContext(ctx); ok {
+				t.Errorf("%s: claims of an anonymous call", method)
+			}
+			return nil, nil
+		})
+		if err != nil {
+			t.Errorf("%s: %v, want the call through without a token", method, err)
+		}
+	}
+}
+
+func TestHttpAuthenticate(t *testing.T) {
+	r := gin.New()
+	r.Use(HttpAuthenticate(testOptions(t)...))
+	var subject string
+	handler := func(c *gin.Context) {
+		subject = SubjectFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	}
+	r.GET("/orders", handler)
+	r.GET("/public", handler)
+
+	for _, tt := range authCases() {
+		t.Run(tt.name, func(t *testing.T) {
+			subject = ""
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if tt.wantCode == codes.OK {
+				if w.Code != http.StatusOK || subject != "alice" {
+					t.Errorf("status = %d with subject %q, want 200 for alice", w.Code, subject)
+				}
+				return
+			}
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", w.Code)
+			}
+			wantChallenge := `Bearer error="invalid_token"`
+			if tt.header == "" || tt.name == "other scheme" {
+				wantChallenge = "Bearer"
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, wantChallenge)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/public", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("public route status = %d, want 200", w.Code)
+	}
+}
+
+func TestWithValidMethods(t *testing.T) {
+	interceptor := Authenticate(append(testOptions(t), WithValidMethods("ES256"))...)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+sign("rsa-1", nil)))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("code = %s, want Unauthenticated for an algorithm outside of the valid methods", status.Code(err))
+	}
+}
+
+func TestWithLeeway(t *testing.T) {
+	interceptor := Authenticate(append(testOptions(t), WithLeeway(time.Minute))...)
+	token := sign("rsa-1", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-10 * time.Second).Unix() })
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Errorf("token expired within the leeway rejected: %v", err)
+	}
+}
+
+func TestClaims(t *testing.T) {
+	claims := &Claims{}
+	err := claims.UnmarshalJSON([]byte(`{"sub":"alice","scope":"orders:read orders:write","roles":["admin"],"tenant":"acme"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "alice" || !claims.HasScope("orders:write") || claims.HasScope("orders") ||
+		!claims.HasRole("admin") || claims.String("tenant") != "acme" || claims.String("roles") != "" {
+		t.Errorf("claims = %+v", claims)
+	}
+}
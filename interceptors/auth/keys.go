@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// jwksReloadInterval bounds how often a JWKS file is re-read when a token has an unknown key ID.
+const jwksReloadInterval = 10 * time.Second
+
+// keySet resolves the verification key of a token by its key ID.
+// A key registered without ID verifies the tokens whose key ID is unknown.
+type keySet struct {
+	mu         sync.RWMutex
+	static     map[string]interface{}
+	jwks       map[string]interface{}
+	jwksFile   string
+	lastReload time.Time
+	now        func() time.Time
+}
+
+func newKeySet() *keySet {
+	return &keySet{static: make(map[string]interface{}), jwks: make(map[string]interface{}), now: time.Now}
+}
+
+func (s *keySet) lookup(kid string) (interface{}, bool) {
+	s.mu.RLock()
+	key, ok := s.find(kid)
+	stale := !ok && s.jwksFile != "" && s.now().Sub(s.lastReload) > jwksReloadInterval
+	s.mu.RUnlock()
+	if !stale {
+		return key, ok
+	}
+
+	// the issuer may have rotated its keys
+	if err := s.loadJWKS(s.jwksFile); err != nil {
+		return nil, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.find(kid)
+}
+
+func (s *keySet) find(kid string) (interface{}, bool) {
+	if key, ok := s.jwks[kid]; ok {
+		return key, true
+	}
+	if key, ok := s.static[kid]; ok {
+		return key, true
+	}
+	key, ok := s.static[""]
+	return key, ok
+}
+
+func (s *keySet) loadJWKS(path string) error {
+	// failed reloads count too, a broken file must not be read on every request
+	s.mu.Lock()
+	s.jwksFile = path
+	s.lastReload = s.now()
+	s.mu.Unlock()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read jwks %s: %w", path, err)
+	}
+	keys, err := parseJWKS(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse jwks %s: %w", path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jwks = keys
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parseJWKS parses a JSON Web Key Set (RFC 7517) into verification keys by key ID.
+// Encryption keys are skipped.
+func parseJWKS(content []byte) (map[string]interface{}, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use == "enc" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		x, err := decodeFixed(k.X, size)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeFixed(k.Y, size)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeFixed(k.X, ed25519.PublicKeySize)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// decodeFixed decodes a coordinate, left-padding it to size bytes.
+func decodeFixed(s string, size int) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > size {
+		return nil, fmt.Errorf("coordinate is %d bytes, want %d", len(b), size)
+	}
+	return append(make([]byte, size-len(b)), b...), nil
+}
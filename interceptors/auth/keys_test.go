@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	testRSAKey = sync.OnceValue(func() *rsa.PrivateKey {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		return key
+	})
+	testECKey = sync.OnceValue(func() *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		return key
+	})
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "RSA", "kid": kid, "n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes())}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	raw, err := key.Bytes()
+	if err != nil {
+		panic(err)
+	}
+	// uncompressed point: 0x04 || x || y
+	size := (len(raw) - 1) / 2
+	return map[string]string{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(raw[1 : 1+size]), "y": b64(raw[1+size:])}
+}
+
+func jwksJSON(keys ...map[string]string) []byte {
+	content, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+func TestParseJWKS(t *testing.T) {
+	edPublic, _, _ := ed25519.GenerateKey(rand.Reader)
+	tests := []struct {
+		name     string
+		content  []byte
+		wantKids []string
+		wantErr  bool
+	}{
+		{name: "rsa", content: jwksJSON(rsaJWK("rsa-1", &testRSAKey().PublicKey)), wantKids: []string{"rsa-1"}},
+		{name: "ec", content: jwksJSON(ecJWK("ec-1", &testECKey().PublicKey)), wantKids: []string{"ec-1"}},
+		{
+			name:     "ed25519",
+			content:  jwksJSON(map[string]string{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": b64(edPublic)}),
+			wantKids: []string{"ed-1"},
+		},
+		{name: "hmac", content: jwksJSON(map[string]string{"kty": "oct", "kid": "hs-1", "k": b64([]byte("secret"))}), wantKids: []string{"hs-1"}},
+		{
+			name: "encryption keys skipped",
+			content: jwksJSON(rsaJWK("sig", &testRSAKey().PublicKey),
+				map[string]string{"kty": "RSA", "kid": "enc", "use": "enc", "n": "", "e": ""}),
+			wantKids: []string{"sig"},
+		},
+		{name: "unsupported key type", content: jwksJSON(map[string]string{"kty": "XYZ", "kid": "x"}), wantErr: true},
+		{name: "unsupported curve", content: jwksJSON(map[string]string{"kty": "EC", "kid": "x", "crv": "P-192"}), wantErr: true},
+		{name: "invalid point", content: jwksJSON(map[string]string{"kty": "EC", "kid": "x", "crv": "P-256", "x": b64([]byte{1}), "y": b64([]byte{2})}), wantErr: true},
+		{name: "invalid json", content: []byte("{"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseJWKS(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if len(keys) != len(tt.wantKids) {
+				t.Errorf("keys = %d, want %d", len(keys), len(tt.wantKids))
+			}
+			for _, kid := range tt.wantKids {
+				if _, ok := keys[kid]; !ok {
+					t.Errorf("key %q missing", kid)
+				}
+			}
+		})
+	}
+}
+
+func TestKeySetReloadsRotatedJWKS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(rsaJWK("old", &testRSAKey().PublicKey)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	keys := newKeySet()
+	keys.now = func() time.Time { return now }
+	if err := keys.loadJWKS(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// the issuer rotates its keys
+	if err := os.WriteFile(path, jwksJSON(ecJWK("new", &testECKey().PublicKey)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := keys.lookup("new"); ok {
+		t.Fatal("jwks reloaded within the reload interval")
+	}
+	now = now.Add(jwksReloadInterval + time.Second)
+	if _, ok := keys.lookup("new"); !ok {
+		t.Fatal("rotated key not found after the reload interval")
+	}
+	if _, ok := keys.lookup("old"); ok {
+		t.Error("key removed from the jwks still known")
+	}
+
+	// a broken file keeps the loaded keys
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(jwksReloadInterval + time.Second)
+	if _, ok := keys.lookup("unknown"); ok {
+		t.Error("unknown key found")
+	}
+	if _, ok := keys.lookup("new"); !ok {
+		t.Error("broken jwks dropped the loaded keys")
+	}
+}
+
+func TestKeySetFallsBackToStaticKey(t *testing.T) {
+	keys := newKeySet()
+	keys.static["kid-1"] = []byte("one")
+	keys.static[""] = []byte("default")
+	tests := []struct {
+		kid  string
+		want string
+	}{
+		{kid: "kid-1", want: "one"},
+		{kid: "other", want: "default"},
+		{kid: "", want: "default"},
+	}
+	for _, tt := range tests {
+		key, ok := keys.lookup(tt.kid)
+		if !ok || string(key.([]byte)) != tt.want {
+			t.Errorf("lookup(%q) = %v, want %q", tt.kid, key, tt.want)
+		}
+	}
+}
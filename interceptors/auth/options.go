@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"time"
+)
+
+var (
+	defaultOptions = &options{
+		validMethods: []string{
+			"RS256", "RS384", "RS512",
+			"PS256", "PS384", "PS512",
+			"ES256", "ES384", "ES512",
+			"EdDSA",
+			"HS256", "HS384", "HS512",
+		},
+		public: map[string]struct{}{
+			"/grpc.health.v1.Health/Check":                                   {},
+			"/grpc.health.v1.Health/Watch":                                   {},
+			"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      {},
+			"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": {},
+		},
+	}
+)
+
+type options struct {
+	keys         *keySet
+	jwksFile     string
+	issuer       string
+	audience     string
+	leeway       time.Duration
+	validMethods []string
+	public       map[string]struct{}
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	optCopy.keys = newKeySet()
+	optCopy.public = make(map[string]struct{}, len(defaultOptions.public))
+	for method := range defaultOptions.public {
+		optCopy.public[method] = struct{}{}
+	}
+	for _, o := range opts {
+		o(optCopy)
+	}
+	return optCopy
+}
+
+type Option func(*options)
+
+// WithJWKSFile verifies tokens with the keys of a local JSON Web Key Set file.
+// The file is read again when a token has an unknown key ID.
+func WithJWKSFile(path string) Option {
+	return func(o *options) {
+		o.jwksFile = path
+	}
+}
+
+// WithStaticKey verifies the tokens with key ID kid using key, which is a []byte for HMAC or a
+// public key (*rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey). An empty kid verifies every
+// token whose key ID is not otherwise known.
+func WithStaticKey(kid string, key interface{}) Option {
+	return func(o *options) {
+		o.keys.static[kid] = key
+	}
+}
+
+// WithIssuer requires the "iss" claim to be issuer.
+func WithIssuer(issuer string) Option {
+	return func(o *options) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience requires the "aud" claim to contain audience.
+func WithAudience(audience string) Option {
+	return func(o *options) {
+		o.audience = audience
+	}
+}
+
+// WithLeeway tolerates clock skew when checking "exp", "nbf" and "iat".
+func WithLeeway(leeway time.Duration) Option {
+	return func(o *options) {
+		o.leeway = leeway
+	}
+}
+
+// WithValidMethods restricts the accepted signing algorithms.
+func WithValidMethods(methods ...string) Option {
+	return func(o *options) {
+		o.validMethods = methods
+	}
+}
+
+// WithPublicMethods lets the given gRPC methods or gin routes ("GET /health") through without a token.
+// Health checks and reflection are public by default.
+func WithPublicMethods(methods ...string) Option {
+	return func(o *options) {
+		for _, method := range methods {
+			o.public[method] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package authz
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Authorizer decides with a policy, reloaded when its file changes. Share one Authorizer between
+// its gRPC and HTTP interceptors and Close it on shutdown to stop watching the policy file.
+type Authorizer struct {
+	*options
+	current   atomic.Pointer[compiledPolicy]
+	done      chan struct{}
+	closeOnce sync.Once
+	stopped   chan struct{}
+}
+
+// NewAuthorizer loads the policy and starts watching the policy file, if any, until Close is called
+// or the context of WithContext is done.
+func NewAuthorizer(opts ...Option) (*Authorizer, error) {
+	a := &Authorizer{options: evaluateOptions(opts), done: make(chan struct{}), stopped: make(chan struct{})}
+	if a.policyFile == "" {
+		if err := a.policy.Validate(); err != nil {
+			return nil, err
+		}
+		a.current.Store(compile(a.policy))
+		close(a.stopped)
+		return a, nil
+	}
+
+	// the modification time is read first so that changes made while loading are reloaded
+	lastModified := modTime(a.policyFile)
+	policy, err := LoadPolicy(a.policyFile)
+	if err != nil {
+		return nil, err
+	}
+	a.current.Store(compile(policy))
+	go a.watch(lastModified)
+	return a, nil
+}
+
+// mustNewAuthorizer is NewAuthorizer panicking if the policy is invalid.
+func mustNewAuthorizer(opts []Option) *Authorizer {
+	a, err := NewAuthorizer(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Close stops watching the policy file, the last loaded policy keeps applying.
+func (a *Authorizer) Close() error {
+	a.closeOnce.Do(func() {
+		close(a.done)
+	})
+	<-a.stopped
+	return nil
+}
+
+func (a *Authorizer) watch(lastModified time.Time) {
+	defer close(a.stopped)
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		modified := modTime(a.policyFile)
+		if modified.Equal(lastModified) {
+			continue
+		}
+		lastModified = modified
+
+		policy, err := LoadPolicy(a.policyFile)
+		if err != nil {
+			a.zapLog.Error("failed to reload authorization policy, keeping the previous one",
+				zap.String("app_name", a.appName), zap.String("path", a.policyFile), zap.Error(err))
+			continue
+		}
+		a.current.Store(compile(policy))
+		a.zapLog.Info("authorization policy reloaded",
+			zap.String("app_name", a.appName), zap.String("path", a.policyFile))
+	}
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func (a *Authorizer) decide(method string, principal Principal, correlationId string) Decision {
+	decision := a.current.Load().decide(method, principal)
+
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("subject", principal.Subject),
+		zap.Bool("allowed", decision.Allowed),
+		zap.String("rule", decision.Rule),
+		zap.String("app_name", a.appName),
+		zap.String("correlation_id", correlationId),
+	}
+	if decision.Allowed {
+		a.zapLog.Info("authorization decision", fields...)
+	} else {
+		a.zapLog.Warn("authorization decision", fields...)
+	}
+	return decision
+}
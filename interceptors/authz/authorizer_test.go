@@ -0,0 +1,122 @@
+package authz
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	adminPolicy   = "rules:\n  - methods: [\"/user.UserService/GetUser\"]\n    roles: [admin]\n"
+	supportPolicy = "rules:\n  - methods: [\"/user.UserService/GetUser\"]\n    roles: [support]\n"
+)
+
+// writePolicy writes content to path with a distinct modification time, so that the change is noticed
+// whatever the resolution of the file system clock.
+func writePolicy(t *testing.T, path string, content string, modified time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func supportAllowed(a *Authorizer) bool {
+	return a.decide("/user.UserService/GetUser", Principal{Roles: []string{"support"}}, "").Allowed
+}
+
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestAuthorizerReloadAndClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	start := time.Now().Add(-time.Hour)
+	writePolicy(t, path, adminPolicy, start)
+
+	a, err := NewAuthorizer(WithPolicyFile(path), WithPollInterval(5*time.Millisecond), WithZapLog(zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if supportAllowed(a) {
+		t.Fatal("support allowed by the initial policy")
+	}
+
+	writePolicy(t, path, supportPolicy, start.Add(time.Minute))
+	if !eventually(func() bool { return supportAllowed(a) }) {
+		t.Fatal("policy change not reloaded")
+	}
+
+	writePolicy(t, path, "rules: [", start.Add(2*time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	if !supportAllowed(a) {
+		t.Fatal("invalid policy replaced the previous one")
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-a.stopped:
+	default:
+		t.Fatal("watch still running after Close")
+	}
+	writePolicy(t, path, adminPolicy, start.Add(3*time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	if !supportAllowed(a) {
+		t.Error("policy reloaded after Close")
+	}
+	if err := a.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestAuthorizerStopsWithContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	writePolicy(t, path, adminPolicy, time.Now().Add(-time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	a, err := NewAuthorizer(WithPolicyFile(path), WithPollInterval(5*time.Millisecond), WithContext(ctx), WithZapLog(zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case <-a.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("watch still running after the context is done")
+	}
+}
+
+func TestNewAuthorizerInvalidPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	writePolicy(t, path, "rules:\n  - methods: [\"*\"]\n", time.Now())
+	if _, err := NewAuthorizer(WithPolicyFile(path), WithZapLog(zap.NewNop())); err == nil {
+		t.Error("invalid policy file accepted")
+	}
+	if _, err := NewAuthorizer(WithPolicy(&Policy{Default: "maybe"}), WithZapLog(zap.NewNop())); err == nil {
+		t.Error("invalid policy accepted")
+	}
+}
+
+func TestAuthorizerWithoutFileCloses(t *testing.T) {
+	a, err := NewAuthorizer(WithPolicy(testPolicy()), WithZapLog(zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
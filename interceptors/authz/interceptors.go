@@ -0,0 +1,83 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func errPermissionDenied() error {
+	return status.Error(codes.PermissionDenied, "permission denied")
+}
+
+// Authorize returns a new unary server interceptor rejecting the callers the policy does not allow
+// with codes.PermissionDenied. Place it after the authentication interceptor.
+// It panics if the policy is invalid. The policy file is watched until the context of WithContext is done,
+// use NewAuthorizer to stop watching it with Close.
+func Authorize(opts ...Option) grpc.UnaryServerInterceptor {
+	return mustNewAuthorizer(opts).Authorize()
+}
+
+// StreamServerInterceptor returns a new streaming server interceptor rejecting the callers the policy does not allow.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	return mustNewAuthorizer(opts).StreamServerInterceptor()
+}
+
+// HttpAuthorize is a Gin middleware rejecting the callers the policy does not allow with 403 Forbidden.
+func HttpAuthorize(opts ...Option) gin.HandlerFunc {
+	return mustNewAuthorizer(opts).HttpAuthorize()
+}
+
+// Authorize returns a new unary server interceptor rejecting the callers the policy does not allow
+// with codes.PermissionDenied. Place it after the authentication interceptor.
+func (a *Authorizer) Authorize() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := a.public[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+		correlationId := reqctx.CorrelationId(ctx)
+		if !a.decide(info.FullMethod, a.principal(ctx), correlationId).Allowed {
+			return nil, errPermissionDenied()
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new streaming server interceptor rejecting the callers the policy does not allow.
+func (a *Authorizer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := a.public[info.FullMethod]; ok {
+			return handler(srv, stream)
+		}
+		ctx := stream.Context()
+		correlationId := reqctx.CorrelationId(ctx)
+		if !a.decide(info.FullMethod, a.principal(ctx), correlationId).Allowed {
+			return errPermissionDenied()
+		}
+		return handler(srv, stream)
+	}
+}
+
+// HttpAuthorize is a Gin middleware rejecting the callers the policy does not allow with 403 Forbidden.
+func (a *Authorizer) HttpAuthorize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		if _, ok := a.public[route]; ok {
+			c.Next()
+			return
+		}
+		correlationId := reqctx.CorrelationId(c)
+		if !a.decide(route, a.principal(c.Request.Context()), correlationId).Allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Permission denied",
+			})
+			return
+		}
+		c.Next()
+	}
+}
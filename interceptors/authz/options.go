@@ -0,0 +1,125 @@
+package authz
+
+import (
+	"context"
+	"time"
+
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/auth"
+	"go.uber.org/zap"
+)
+
+const defaultPollInterval = 5 * time.Second
+
+var (
+	defaultOptions = &options{
+		ctx:          context.Background(),
+		policy:       &Policy{},
+		pollInterval: defaultPollInterval,
+		principal:    ClaimsPrincipal,
+		public: map[string]struct{}{
+			"/grpc.health.v1.Health/Check":                                   {},
+			"/grpc.health.v1.Health/Watch":                                   {},
+			"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      {},
+			"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": {},
+		},
+	}
+)
+
+type options struct {
+	ctx          context.Context
+	policy       *Policy
+	policyFile   string
+	pollInterval time.Duration
+	principal    func(ctx context.Context) Principal
+	public       map[string]struct{}
+	zapLog       *zap.Logger
+	appName      string
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	optCopy.public = make(map[string]struct{}, len(defaultOptions.public))
+	for method := range defaultOptions.public {
+		optCopy.public[method] = struct{}{}
+	}
+	for _, o := range opts {
+		o(optCopy)
+	}
+	if optCopy.zapLog == nil {
+		optCopy.zapLog, _ = zap.NewProduction()
+	}
+	return optCopy
+}
+
+// ClaimsPrincipal is the principal of the JWT claims put in the context by the auth package.
+func ClaimsPrincipal(ctx context.Context) Principal {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return Principal{}
+	}
+	return Principal{Subject: claims.Subject, Roles: claims.Roles, Scopes: claims.Scopes()}
+}
+
+type Option func(*options)
+
+// WithPolicy authorizes with a fixed policy.
+func WithPolicy(policy *Policy) Option {
+	return func(o *options) {
+		if policy != nil {
+			o.policy = policy
+		}
+	}
+}
+
+// WithPolicyFile authorizes with the policy of a YAML file, reloaded when it changes.
+// An invalid file is reported and the previous policy stays in place.
+func WithPolicyFile(path string) Option {
+	return func(o *options) {
+		o.policyFile = path
+	}
+}
+
+// WithContext stops watching the policy file when ctx is done, the last loaded policy keeps applying.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithPollInterval sets how often the policy file is checked for changes.
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = interval
+	}
+}
+
+// WithPrincipal sets how the caller identity is read from the context, the JWT claims by default.
+func WithPrincipal(principal func(ctx context.Context) Principal) Option {
+	return func(o *options) {
+		o.principal = principal
+	}
+}
+
+// WithPublicMethods never authorizes the given gRPC methods or gin routes ("GET /health").
+// Health checks and reflection are public by default.
+func WithPublicMethods(methods ...string) Option {
+	return func(o *options) {
+		for _, method := range methods {
+			o.public[method] = struct{}{}
+		}
+	}
+}
+
+// WithZapLog sets the logger of the policy decisions.
+func WithZapLog(zapLog *zap.Logger) Option {
+	return func(o *options) {
+		o.zapLog = zapLog
+	}
+}
+
+func WithAppName(appName string) Option {
+	return func(o *options) {
+		o.appName = appName
+	}
+}
@@ -0,0 +1,140 @@
+package authz
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Policy authorizes callers by full gRPC method ("/pkg.Service/Method") or gin route ("GET /users/:id").
+// The most specific rule applies: exact method, then whole service ("/pkg.Service/*"), then "*".
+// A rule allows callers holding one of its roles or one of its scopes. Methods without a rule
+// get the Default effect, deny when empty. A method pattern belongs to a single rule.
+//
+// Example:
+//
+//	default: deny
+//	rules:
+//	  - methods: ["/user.UserService/DeleteUser", "DELETE /users/:id"]
+//	    roles: [admin]
+//	  - methods: ["/user.UserService/*"]
+//	    roles: [admin, support]
+//	    scopes: [users:read]
+type Policy struct {
+	Default string `yaml:"default"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	Methods []string `yaml:"methods"`
+	Roles   []string `yaml:"roles"`
+	Scopes  []string `yaml:"scopes"`
+	// Allow lets every caller through, authenticated or not.
+	Allow bool `yaml:"allow"`
+}
+
+// LoadPolicy reads a policy from a YAML file.
+func LoadPolicy(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy %s: %w", path, err)
+	}
+	policy := &Policy{}
+	if err := yaml.Unmarshal(content, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+func (p *Policy) Validate() error {
+	if p.Default != "" && p.Default != EffectAllow && p.Default != EffectDeny {
+		return fmt.Errorf("default must be %q or %q, got %q", EffectAllow, EffectDeny, p.Default)
+	}
+	ruleOf := make(map[string]int)
+	for i, rule := range p.Rules {
+		if len(rule.Methods) == 0 {
+			return fmt.Errorf("rule %d has no methods", i)
+		}
+		for _, method := range rule.Methods {
+			if previous, ok := ruleOf[method]; ok {
+				return fmt.Errorf("method %q of rule %d is already in rule %d", method, i, previous)
+			}
+			ruleOf[method] = i
+		}
+		if !rule.Allow && len(rule.Roles) == 0 && len(rule.Scopes) == 0 {
+			return fmt.Errorf("rule %d allows nobody, set roles, scopes or allow", i)
+		}
+	}
+	return nil
+}
+
+// Principal is the identity a decision is made for.
+type Principal struct {
+	Subject string
+	Roles   []string
+	Scopes  []string
+}
+
+// Decision is the outcome of a policy evaluation.
+type Decision struct {
+	Allowed bool
+	// Rule is the method pattern of the applied rule, empty when the default applied.
+	Rule string
+}
+
+// compiledPolicy indexes the rules of a policy by method pattern.
+type compiledPolicy struct {
+	allowByDefault bool
+	rules          map[string]*Rule
+}
+
+func compile(p *Policy) *compiledPolicy {
+	c := &compiledPolicy{
+		allowByDefault: p.Default == EffectAllow,
+		rules:          make(map[string]*Rule),
+	}
+	for i := range p.Rules {
+		for _, method := range p.Rules[i].Methods {
+			c.rules[method] = &p.Rules[i]
+		}
+	}
+	return c
+}
+
+func (c *compiledPolicy) decide(method string, principal Principal) Decision {
+	pattern, rule := c.lookup(method)
+	if rule == nil {
+		return Decision{Allowed: c.allowByDefault}
+	}
+	allowed := rule.Allow ||
+		slices.ContainsFunc(principal.Roles, func(role string) bool { return slices.Contains(rule.Roles, role) }) ||
+		slices.ContainsFunc(principal.Scopes, func(scope string) bool { return slices.Contains(rule.Scopes, scope) })
+	return Decision{Allowed: allowed, Rule: pattern}
+}
+
+func (c *compiledPolicy) lookup(method string) (string, *Rule) {
+	if rule, ok := c.rules[method]; ok {
+		return method, rule
+	}
+	if i := strings.LastIndex(method, "/"); i >= 0 && strings.HasPrefix(method, "/") {
+		service := method[:i+1] + "*"
+		if rule, ok := c.rules[service]; ok {
+			return service, rule
+		}
+	}
+	if rule, ok := c.rules["*"]; ok {
+		return "*", rule
+	}
+	return "", nil
+}
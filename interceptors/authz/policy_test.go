@@ -0,0 +1,121 @@
+package authz
+
+import (
+	"strings"
+	"testing"
+)
+
+func testPolicy() *Policy {
+	return &Policy{
+		Rules: []Rule{
+			{Methods: []string{"/user.UserService/DeleteUser", "DELETE /users/:id"}, Roles: []string{"admin"}},
+			{Methods: []string{"/user.UserService/*"}, Roles: []string{"admin", "support"}, Scopes: []string{"users:read"}},
+			{Methods: []string{"/health.Public/*", "GET /status"}, Allow: true},
+		},
+	}
+}
+
+func TestPolicyDecide(t *testing.T) {
+	admin := Principal{Subject: "a", Roles: []string{"admin"}}
+	support := Principal{Subject: "s", Roles: []string{"support"}}
+	reader := Principal{Subject: "r", Scopes: []string{"users:read"}}
+	anonymous := Principal{}
+
+	tests := []struct {
+		name        string
+		policy      *Policy
+		method      string
+		principal   Principal
+		wantAllowed bool
+		wantRule    string
+	}{
+		{name: "exact method allowed", method: "/user.UserService/DeleteUser", principal: admin, wantAllowed: true, wantRule: "/user.UserService/DeleteUser"},
+		{name: "exact method takes precedence over service", method: "/user.UserService/DeleteUser", principal: support, wantAllowed: false, wantRule: "/user.UserService/DeleteUser"},
+		{name: "service wildcard by role", method: "/user.UserService/GetUser", principal: support, wantAllowed: true, wantRule: "/user.UserService/*"},
+		{name: "service wildcard by scope", method: "/user.UserService/GetUser", principal: reader, wantAllowed: true, wantRule: "/user.UserService/*"},
+		{name: "service wildcard denies others", method: "/user.UserService/GetUser", principal: anonymous, wantAllowed: false, wantRule: "/user.UserService/*"},
+		{name: "gin route", method: "DELETE /users/:id", principal: admin, wantAllowed: true, wantRule: "DELETE /users/:id"},
+		{name: "gin route denied", method: "DELETE /users/:id", principal: reader, wantAllowed: false, wantRule: "DELETE /users/:id"},
+		{name: "allow rule lets anonymous callers through", method: "GET /status", principal: anonymous, wantAllowed: true, wantRule: "GET /status"},
+		{name: "allow rule on service", method: "/health.Public/Ping", principal: anonymous, wantAllowed: true, wantRule: "/health.Public/*"},
+		{name: "unmatched method denied by default", method: "/order.OrderService/GetOrder", principal: admin, wantAllowed: false},
+		{name: "unmatched gin route denied by default", method: "GET /orders", principal: admin, wantAllowed: false},
+		{name: "other service of the same package", method: "/user.UserAdmin/GetUser", principal: support, wantAllowed: false},
+		{
+			name:        "default allow",
+			policy:      &Policy{Default: EffectAllow, Rules: testPolicy().Rules},
+			method:      "/order.OrderService/GetOrder",
+			principal:   anonymous,
+			wantAllowed: true,
+		},
+		{
+			name: "catch-all rule",
+			policy: &Policy{Rules: append(testPolicy().Rules,
+				Rule{Methods: []string{"*"}, Scopes: []string{"all"}})},
+			method:      "/order.OrderService/GetOrder",
+			principal:   Principal{Scopes: []string{"all"}},
+			wantAllowed: true,
+			wantRule:    "*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			if policy == nil {
+				policy = testPolicy()
+			}
+			if err := policy.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			decision := compile(policy).decide(tt.method, tt.principal)
+			if decision.Allowed != tt.wantAllowed {
+				t.Errorf("allowed = %t, want %t", decision.Allowed, tt.wantAllowed)
+			}
+			if decision.Rule != tt.wantRule {
+				t.Errorf("rule = %q, want %q", decision.Rule, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *Policy
+		wantErr string
+	}{
+		{name: "valid", policy: testPolicy()},
+		{name: "unknown default", policy: &Policy{Default: "maybe"}, wantErr: "default must be"},
+		{name: "rule without methods", policy: &Policy{Rules: []Rule{{Roles: []string{"admin"}}}}, wantErr: "has no methods"},
+		{name: "rule allowing nobody", policy: &Policy{Rules: []Rule{{Methods: []string{"*"}}}}, wantErr: "allows nobody"},
+		{
+			name: "method repeated across rules",
+			policy: &Policy{Rules: []Rule{
+				{Methods: []string{"/user.UserService/DeleteUser"}, Roles: []string{"admin"}},
+				{Methods: []string{"/user.UserService/DeleteUser"}, Allow: true},
+			}},
+			wantErr: `method "/user.UserService/DeleteUser" of rule 1 is already in rule 0`,
+		},
+		{
+			name: "method repeated in a rule",
+			policy: &Policy{Rules: []Rule{
+				{Methods: []string{"GET /users", "GET /users"}, Roles: []string{"admin"}},
+			}},
+			wantErr: `method "GET /users" of rule 0 is already in rule 0`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
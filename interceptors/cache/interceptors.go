@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// cacheKey derives the key of a request to method. The scope and the request are hashed so that
+// credentials do not appear in the keys.
+func cacheKey(method string, scope string, request []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write(request)
+	return method + "|" + hex.EncodeToString(h.Sum(nil))
+}
+
+func bypass(values ...string) bool {
+	for _, v := range values {
+		v = strings.ToLower(v)
+		if strings.Contains(v, "no-cache") || strings.Contains(v, "no-store") {
+			return true
+		}
+	}
+	return false
+}
+
+// Cache returns a new unary server interceptor caching the responses of the methods set with WithMethod.
+// The cache key is derived from the method, the caller (see WithKeyFunc) and the deterministic encoding
+// of the request. A "cache-control: no-cache" metadata bypasses the lookup. Errors are never cached.
+func Cache(opts ...Option) grpc.UnaryServerInterceptor {
+	o := evaluateOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ttl, ok := o.methods[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+		reqMsg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		encodedReq, err := proto.MarshalOptions{Deterministic: true}.Marshal(reqMsg)
+		if err != nil {
+			return handler(ctx, req)
+		}
+		authorization := ""
+		if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+		key := cacheKey(info.FullMethod, o.scope(ctx, authorization), encodedReq)
+
+		result := "bypass"
+		if !bypass(metadata.ValueFromIncomingContext(ctx, BypassHeader)...) {
+			result = "miss"
+			if resp, ok := lookup(ctx, o.store, key); ok {
+				ctxzap.AddFields(ctx, zap.String("cache", "hit"))
+				return resp, nil
+			}
+		}
+		ctxzap.AddFields(ctx, zap.String("cache", result))
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		respMsg, ok := resp.(proto.Message)
+		if !ok {
+			return resp, nil
+		}
+		encodedResp, err := anypb.New(respMsg)
+		if err != nil {
+			return resp, nil
+		}
+		response, err := proto.Marshal(encodedResp)
+		if err != nil {
+			return resp, nil
+		}
+		_ = o.store.Set(ctx, key, Entry{Response: response}, ttl)
+		return resp, nil
+	}
+}
+
+// lookup returns the cached response of key. Store and decoding errors count as misses.
+func lookup(ctx context.Context, store Store, key string) (interface{}, bool) {
+	entry, ok, err := store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	encodedResp := &anypb.Any{}
+	if err := proto.Unmarshal(entry.Response, encodedResp); err != nil {
+		return nil, false
+	}
+	resp, err := encodedResp.UnmarshalNew()
+	if err != nil {
+		return nil, false
+	}
+	return resp, true
+}
+
+// bodyWriter copies the response body while it is written.
+type bodyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// HttpCache is a Gin middleware caching the 2xx responses of the routes set with WithMethod.
+// The cache key is derived from the route, the caller (see WithKeyFunc), the path, the sorted query and
+// the body. Bodies over WithMaxBodyBytes are rejected with 413. A "Cache-Control: no-cache" header bypasses the lookup. The X-Cache header reports HIT or MISS.
+func HttpCache(opts ...Option) gin.HandlerFunc {
+	o := evaluateOptions(opts)
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		ttl, ok := o.methods[route]
+		if !ok {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, o.maxBodyBytes))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "Request body too large",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		// Query().Encode() sorts the parameters so that their order does not matter
+		request := c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() + "\x00" + string(body)
+		ctx := c.Request.Context()
+		key := cacheKey(route, o.scope(ctx, c.GetHeader("Authorization")), []byte(request))
+
+		result := "bypass"
+		if !bypass(c.Request.Header.Values(BypassHeader)...) {
+			result = "miss"
+			if entry, ok, err := o.store.Get(ctx, key); err == nil && ok {
+				for k, values := range entry.Header {
+					for _, v := range values {
+						c.Writer.Header().Add(k, v)
+					}
+				}
+				c.Header(StatusHeader, "HIT")
+				interceptors.AddHttpLogFields(c, zap.String("cache", "hit"))
+				c.Status(entry.StatusCode)
+				_, _ = c.Writer.Write(entry.Response)
+				c.Abort()
+				return
+			}
+		}
+		c.Header(StatusHeader, "MISS")
+		interceptors.AddHttpLogFields(c, zap.String("cache", result))
+
+		// only the headers set by the handler are cached, not the ones of this request (e.g. correlation ID)
+		headerBefore := c.Writer.Header().Clone()
+		writer := &bodyWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		c.Next()
+
+		statusCode := c.Writer.Status()
+		if statusCode < 200 || statusCode >= 300 {
+			return
+		}
+		header := make(map[string][]string)
+		for k, v := range c.Writer.Header() {
+			if _, ok := headerBefore[k]; !ok {
+				header[k] = v
+			}
+		}
+		_ = o.store.Set(ctx, key, Entry{
+			Response:   writer.body.Bytes(),
+			StatusCode: statusCode,
+			Header:     header,
+		}, ttl)
+	}
+}
@@ -0,0 +1,295 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const testMethod = "/user.UserService/GetUser"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func grpcContext(subject string, md ...string) context.Context {
+	ctx := context.Background()
+	if subject != "" {
+		ctx = reqctx.WithMeta(ctx, reqctx.RequestMeta{Subject: subject, Tenant: "acme"})
+	}
+	if len(md) > 0 {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(md...))
+	}
+	return ctx
+}
+
+// countingHandler answers with the number of calls it handled.
+func countingHandler(calls *atomic.Int64) grpc.UnaryHandler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.Int64(calls.Add(1)), nil
+	}
+}
+
+func callGrpc(t *testing.T, interceptor grpc.UnaryServerInterceptor, ctx context.Context, req string, handler grpc.UnaryHandler) (int64, error) {
+	t.Helper()
+	resp, err := interceptor(ctx, wrapperspb.String(req), &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+	if err != nil {
+		return 0, err
+	}
+	return resp.(*wrapperspb.Int64Value).GetValue(), nil
+}
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		secondCtx context.Context
+		secondReq string
+		want      int64
+	}{
+		{name: "hit", secondCtx: grpcContext("alice"), secondReq: "1", want: 1},
+		{name: "miss on another request", secondCtx: grpcContext("alice"), secondReq: "2", want: 2},
+		{name: "miss for another caller", secondCtx: grpcContext("bob"), secondReq: "1", want: 2},
+		{name: "miss for an anonymous caller", secondCtx: grpcContext(""), secondReq: "1", want: 2},
+		{name: "bypass", secondCtx: grpcContext("alice", BypassHeader, "no-cache"), secondReq: "1", want: 2},
+		{
+			name:      "shared with a constant key func",
+			opts:      []Option{WithKeyFunc(func(ctx context.Context) string { return "" })},
+			secondCtx: grpcContext("bob"),
+			secondReq: "1",
+			want:      1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := &atomic.Int64{}
+			interceptor := Cache(append([]Option{WithMethod(testMethod, time.Minute)}, tt.opts...)...)
+			if _, err := callGrpc(t, interceptor, grpcContext("alice"), "1", countingHandler(calls)); err != nil {
+				t.Fatal(err)
+			}
+			got, err := callGrpc(t, interceptor, tt.secondCtx, tt.secondReq, countingHandler(calls))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("response = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheAnonymousCredentials(t *testing.T) {
+	calls := &atomic.Int64{}
+	interceptor := Cache(WithMethod(testMethod, time.Minute))
+	first, _ := callGrpc(t, interceptor, grpcContext("", "authorization", "Bearer a"), "1", countingHandler(calls))
+	second, _ := callGrpc(t, interceptor, grpcContext("", "authorization", "Bearer b"), "1", countingHandler(calls))
+	if first == second {
+		t.Error("response of a token served to another token")
+	}
+}
+
+func TestCacheSkipsErrorsAndOtherMethods(t *testing.T) {
+	calls := &atomic.Int64{}
+	interceptor := Cache(WithMethod(testMethod, time.Minute))
+	_, err := callGrpc(t, interceptor, grpcContext("alice"), "1", func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls.Add(1)
+		return nil, status.Error(codes.Unavailable, "down")
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("code = %s, want Unavailable", status.Code(err))
+	}
+	if got, _ := callGrpc(t, interceptor, grpcContext("alice"), "1", countingHandler(calls)); got != 2 {
+		t.Errorf("response after an error = %d, want 2", got)
+	}
+
+	other := &atomic.Int64{}
+	for i := 0; i < 2; i++ {
+		_, _ = interceptor(grpcContext("alice"), wrapperspb.String("1"), &grpc.UnaryServerInfo{FullMethod: "/user.UserService/ListUsers"}, countingHandler(other))
+	}
+	if other.Load() != 2 {
+		t.Errorf("uncached method handled %d times, want 2", other.Load())
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	store, clock := newTestStore(1024)
+	calls := &atomic.Int64{}
+	interceptor := Cache(WithStore(store), WithMethod(testMethod, time.Minute))
+	_, _ = callGrpc(t, interceptor, grpcContext("alice"), "1", countingHandler(calls))
+	clock.Advance(time.Minute)
+	if got, _ := callGrpc(t, interceptor, grpcContext("alice"), "1", countingHandler(calls)); got != 2 {
+		t.Errorf("response after expiry = %d, want 2", got)
+	}
+}
+
+func newRouter(calls *atomic.Int64, opts ...Option) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		// a header set before the cache, as the correlation ID is, must not be replayed
+		c.Header("X-Request-Id", c.GetHeader("X-Request-Id"))
+		if subject := c.GetHeader("X-Subject"); subject != "" {
+			c.Request = c.Request.WithContext(reqctx.WithMeta(c.Request.Context(), reqctx.RequestMeta{Subject: subject}))
+		}
+	}, HttpCache(append([]Option{WithMethod("GET /users/:id", time.Minute), WithMethod("POST /search", time.Minute)}, opts...)...))
+	r.GET("/users/:id", func(c *gin.Context) {
+		call := calls.Add(1)
+		if c.Param("id") == "missing" {
+			c.JSON(http.StatusNotFound, gin.H{"call": call})
+			return
+		}
+		c.Header("X-User", c.Param("id"))
+		c.JSON(http.StatusOK, gin.H{"call": call})
+	})
+	r.POST("/search", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"call": calls.Add(1)})
+	})
+	return r
+}
+
+func doHttp(r *gin.Engine, target string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHttpCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		first        string
+		second       string
+		secondHeader []string
+		wantStatus   int
+		wantBody     string
+		wantCache    string
+	}{
+		{name: "hit", first: "/users/1", second: "/users/1", wantStatus: http.StatusOK, wantBody: `{"call":1}`, wantCache: "HIT"},
+		{name: "query order ignored", first: "/users/1?a=1&b=2", second: "/users/1?b=2&a=1", wantStatus: http.StatusOK, wantBody: `{"call":1}`, wantCache: "HIT"},
+		{name: "miss on another path", first: "/users/1", second: "/users/2", wantStatus: http.StatusOK, wantBody: `{"call":2}`, wantCache: "MISS"},
+		{
+			name:         "miss for another caller",
+			first:        "/users/1",
+			second:       "/users/1",
+			secondHeader: []string{"X-Subject", "bob"},
+			wantStatus:   http.StatusOK,
+			wantBody:     `{"call":2}`,
+			wantCache:    "MISS",
+		},
+		{
+			name:         "bypass",
+			first:        "/users/1",
+			second:       "/users/1",
+			secondHeader: []string{BypassHeader, "no-cache"},
+			wantStatus:   http.StatusOK,
+			wantBody:     `{"call":2}`,
+			wantCache:    "MISS",
+		},
+		{name: "non-2xx not cached", first: "/users/missing", second: "/users/missing", wantStatus: http.StatusNotFound, wantBody: `{"call":2}`, wantCache: "MISS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRouter(&atomic.Int64{})
+			doHttp(r, tt.first, "X-Subject", "alice")
+			w := doHttp(r, tt.second, append([]string{"X-Subject", "alice"}, tt.secondHeader...)...)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+			if got := w.Header().Get(StatusHeader); got != tt.wantCache {
+				t.Errorf("%s = %q, want %q", StatusHeader, got, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestHttpCacheAnonymousCredentials(t *testing.T) {
+	r := newRouter(&atomic.Int64{})
+	doHttp(r, "/users/1", "Authorization", "Bearer a")
+	if w := doHttp(r, "/users/1", "Authorization", "Bearer b"); w.Header().Get(StatusHeader) != "MISS" {
+		t.Error("response of a token served to another token")
+	}
+	if w := doHttp(r, "/users/1", "Authorization", "Bearer a"); w.Header().Get(StatusHeader) != "HIT" {
+		t.Error("response of a token not replayed for the same token")
+	}
+}
+
+func TestHttpCacheReplaysHandlerHeaders(t *testing.T) {
+	r := newRouter(&atomic.Int64{})
+	doHttp(r, "/users/1", "X-Request-Id", "first")
+	w := doHttp(r, "/users/1", "X-Request-Id", "second")
+	if w.Header().Get(StatusHeader) != "HIT" {
+		t.Fatalf("%s = %q, want HIT", StatusHeader, w.Header().Get(StatusHeader))
+	}
+	if got := w.Header().Get("X-User"); got != "1" {
+		t.Errorf("X-User = %q, want the header of the handler", got)
+	}
+	if got := w.Header().Values("X-Request-Id"); len(got) != 1 || got[0] != "second" {
+		t.Errorf("X-Request-Id = %v, want only the one of this request", got)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("Content-Type = %q, want the one of the handler", got)
+	}
+}
+
+func TestHttpCacheBody(t *testing.T) {
+	calls := &atomic.Int64{}
+	r := newRouter(calls, WithMaxBodyBytes(16))
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(body)))
+		return w
+	}
+	post(`{"q":"a"}`)
+	if w := post(`{"q":"a"}`); w.Body.String() != `{"call":1}` {
+		t.Errorf("same body = %s, want the cached response", w.Body.String())
+	}
+	if w := post(`{"q":"b"}`); w.Body.String() != `{"call":2}` {
+		t.Errorf("other body = %s, want a fresh response", w.Body.String())
+	}
+	if w := post(`{"q":"` + strings.Repeat("a", 32) + `"}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler ran %d times, want 2", calls.Load())
+	}
+}
+
+// failingStore fails every operation.
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) (*Entry, bool, error) {
+	return nil, false, errors.New("store down")
+}
+
+func (failingStore) Set(context.Context, string, Entry, time.Duration) error {
+	return errors.New("store down")
+}
+
+func TestCacheStoreErrorsAreMisses(t *testing.T) {
+	calls := &atomic.Int64{}
+	interceptor := Cache(WithStore(failingStore{}), WithMethod(testMethod, time.Minute))
+	for want := int64(1); want <= 2; want++ {
+		got, err := callGrpc(t, interceptor, grpcContext("alice"), "1", countingHandler(calls))
+		if err != nil || got != want {
+			t.Errorf("response = %d, %v, want %d", got, err, want)
+		}
+	}
+}
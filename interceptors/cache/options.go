@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+)
+
+const (
+	// BypassHeader skips the cache lookup when it is "no-cache", in gRPC metadata and HTTP headers.
+	// The fresh response still replaces the cached one.
+	BypassHeader = "cache-control"
+	// StatusHeader tells HTTP clients whether the response was a "HIT" or a "MISS".
+	StatusHeader = "X-Cache"
+	// defaultMaxBytes bounds the default memory store.
+	defaultMaxBytes = 64 << 20
+)
+
+var (
+	defaultOptions = &options{
+		maxBytes:     defaultMaxBytes,
+		maxBodyBytes: 1 << 20,
+	}
+)
+
+type options struct {
+	store        Store
+	maxBytes     int
+	methods      map[string]time.Duration
+	keyFunc      func(ctx context.Context) string
+	maxBodyBytes int64
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	optCopy.methods = make(map[string]time.Duration)
+	for _, o := range opts {
+		o(optCopy)
+	}
+	if optCopy.store == nil {
+		optCopy.store = NewMemoryStore(optCopy.maxBytes)
+	}
+	return optCopy
+}
+
+type Option func(*options)
+
+// WithStore keeps the responses in store instead of a 64MB memory store.
+func WithStore(store Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// WithMaxBytes bounds the size of the default memory store, 64MB by default.
+// It has no effect with WithStore.
+func WithMaxBytes(maxBytes int) Option {
+	return func(o *options) {
+		o.maxBytes = maxBytes
+	}
+}
+
+// scope returns the part of the cache key identifying who the response of a request handled with ctx
+// is for. By default responses are cached per tenant and subject (see reqctx.RequestMeta), and per
+// credentials for requests carrying an authorization that was not verified.
+func (o *options) scope(ctx context.Context, authorization string) string {
+	if o.keyFunc != nil {
+		return o.keyFunc(ctx)
+	}
+	meta, _ := reqctx.FromContext(ctx)
+	switch {
+	case meta.Subject != "":
+		return meta.Tenant + "|sub:" + meta.Subject
+	case authorization != "":
+		return meta.Tenant + "|auth:" + authorization
+	}
+	return meta.Tenant + "|anonymous"
+}
+
+// WithMethod caches the responses of a gRPC method ("/pkg.Service/Method") or
+// gin route ("GET /users/:id") for ttl. Nothing is cached by default.
+// Responses are only shared between the requests of the same caller, see WithKeyFunc.
+func WithMethod(method string, ttl time.Duration) Option {
+	return func(o *options) {
+		o.methods[method] = ttl
+	}
+}
+
+// WithKeyFunc replaces the caller part of the cache keys, the tenant and subject of the request by default.
+// Responses are shared between the requests for which keyFunc returns the same value, return a constant
+// for the methods whose response is the same for every caller.
+func WithKeyFunc(keyFunc func(ctx context.Context) string) Option {
+	return func(o *options) {
+		o.keyFunc = keyFunc
+	}
+}
+
+// WithMaxBodyBytes caps the size of the HTTP request bodies read to derive the cache key, 1MB by default.
+// Larger bodies are rejected with 413 Request Entity Too Large.
+func WithMaxBodyBytes(maxBodyBytes int64) Option {
+	return func(o *options) {
+		o.maxBodyBytes = maxBodyBytes
+	}
+}
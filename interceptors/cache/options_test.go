@@ -0,0 +1,36 @@
+package cache
+
+import "testing"
+
+func TestEvaluateOptionsStore(t *testing.T) {
+	custom := NewMemoryStore(1)
+	tests := []struct {
+		name         string
+		opts         []Option
+		wantStore    Store
+		wantMaxBytes int
+	}{
+		{name: "default memory store", wantMaxBytes: defaultMaxBytes},
+		{name: "max bytes", opts: []Option{WithMaxBytes(1024)}, wantMaxBytes: 1024},
+		{name: "store then max bytes", opts: []Option{WithStore(custom), WithMaxBytes(1024)}, wantStore: custom},
+		{name: "max bytes then store", opts: []Option{WithMaxBytes(1024), WithStore(custom)}, wantStore: custom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := evaluateOptions(tt.opts)
+			if tt.wantStore != nil {
+				if o.store != tt.wantStore {
+					t.Errorf("store = %v, want the custom store", o.store)
+				}
+				return
+			}
+			memory, ok := o.store.(*MemoryStore)
+			if !ok {
+				t.Fatalf("store = %T, want *MemoryStore", o.store)
+			}
+			if memory.maxBytes != tt.wantMaxBytes {
+				t.Errorf("max bytes = %d, want %d", memory.maxBytes, tt.wantMaxBytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	// Response is the encoded response, an anypb.Any for gRPC and the body for HTTP.
+	Response []byte
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Header holds the HTTP response headers.
+	Header map[string][]string
+}
+
+func (e *Entry) size() int {
+	size := len(e.Response)
+	for k, values := range e.Header {
+		size += len(k)
+		for _, v := range values {
+			size += len(v)
+		}
+	}
+	return size
+}
+
+// Store keeps the cached responses. Implement it on a shared backend (e.g. Redis)
+// so that replicas share their cache.
+type Store interface {
+	// Get returns the entry of key, false when it is unknown or expired.
+	Get(ctx context.Context, key string) (*Entry, bool, error)
+	// Set caches entry under key for ttl.
+	Set(ctx context.Context, key string, entry Entry, ttl time.Duration) error
+}
+
+type item struct {
+	key       string
+	entry     Entry
+	size      int
+	expiresAt time.Time
+}
+
+// MemoryStore is a least recently used cache of a single process bounded in bytes.
+type MemoryStore struct {
+	mu       sync.Mutex
+	maxBytes int
+	bytes    int
+	order    *list.List
+	items    map[string]*list.Element
+	now      func() time.Time
+}
+
+// NewMemoryStore returns a MemoryStore holding up to maxBytes of responses,
+// the least recently used ones are evicted first.
+func NewMemoryStore(maxBytes int) *MemoryStore {
+	return &MemoryStore{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (*Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	it := element.Value.(*item)
+	if !s.now().Before(it.expiresAt) {
+		s.remove(element)
+		return nil, false, nil
+	}
+	s.order.MoveToFront(element)
+	entry := it.entry
+	return &entry, true, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, entry Entry, ttl time.Duration) error {
+	size := len(key) + entry.size()
+	if size > s.maxBytes {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.items[key]; ok {
+		s.remove(element)
+	}
+	s.items[key] = s.order.PushFront(&item{key: key, entry: entry, size: size, expiresAt: s.now().Add(ttl)})
+	s.bytes += size
+	for s.bytes > s.maxBytes {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+func (s *MemoryStore) remove(element *list.Element) {
+	it := s.order.Remove(element).(*item)
+	delete(s.items, it.key)
+	s.bytes -= it.size
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source for MemoryStore.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestStore(maxBytes int) (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	store := NewMemoryStore(maxBytes)
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		wantHit bool
+	}{
+		{name: "fresh", elapsed: 0, wantHit: true},
+		{name: "before expiry", elapsed: time.Minute - time.Nanosecond, wantHit: true},
+		{name: "at expiry", elapsed: time.Minute, wantHit: false},
+		{name: "after expiry", elapsed: time.Hour, wantHit: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := newTestStore(1024)
+			_ = store.Set(context.Background(), "k", Entry{Response: []byte("v")}, time.Minute)
+			clock.Advance(tt.elapsed)
+			_, hit, err := store.Get(context.Background(), "k")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hit != tt.wantHit {
+				t.Errorf("hit = %t, want %t", hit, tt.wantHit)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	// each entry takes 1 byte of key and 9 bytes of response
+	store, _ := newTestStore(30)
+	entry := Entry{Response: []byte(strings.Repeat("x", 9))}
+	for _, key := range []string{"a", "b", "c"} {
+		_ = store.Set(context.Background(), key, entry, time.Minute)
+	}
+	// a becomes the most recently used, b is evicted by d
+	if _, hit, _ := store.Get(context.Background(), "a"); !hit {
+		t.Fatal("a evicted before the store is full")
+	}
+	_ = store.Set(context.Background(), "d", entry, time.Minute)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		if _, hit, _ := store.Get(context.Background(), key); hit != want {
+			t.Errorf("%s cached = %t, want %t", key, hit, want)
+		}
+	}
+	if store.bytes != 30 {
+		t.Errorf("bytes = %d, want 30", store.bytes)
+	}
+}
+
+func TestMemoryStoreSkipsOversizedEntries(t *testing.T) {
+	store, _ := newTestStore(10)
+	_ = store.Set(context.Background(), "small", Entry{Response: []byte("v")}, time.Minute)
+	_ = store.Set(context.Background(), "large", Entry{Response: []byte(strings.Repeat("x", 64))}, time.Minute)
+	if _, hit, _ := store.Get(context.Background(), "large"); hit {
+		t.Error("entry larger than the store cached")
+	}
+	if _, hit, _ := store.Get(context.Background(), "small"); !hit {
+		t.Error("oversized entry evicted the others")
+	}
+}
+
+func TestMemoryStoreCountsHeaders(t *testing.T) {
+	store, _ := newTestStore(1024)
+	_ = store.Set(context.Background(), "k", Entry{Response: []byte("body"), Header: map[string][]string{"X-A": {"12"}}}, time.Minute)
+	if want := len("k") + len("body") + len("X-A") + len("12"); store.bytes != want {
+		t.Errorf("bytes = %d, want %d", store.bytes, want)
+	}
+}
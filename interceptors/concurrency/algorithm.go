@@ -0,0 +1,166 @@
+package concurrency
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Algorithm adapts the concurrency limit from the latency observed on completed requests.
+// Implementations must be safe for concurrent use.
+type Algorithm interface {
+	// Limit returns the current number of requests allowed in flight.
+	Limit() int
+	// OnSample records a completed request. inflight is the number of requests in flight
+	// when it started and dropped reports that it failed because of overload.
+	OnSample(rtt time.Duration, inflight int, dropped bool)
+}
+
+// AIMDConfig configures the additive-increase/multiplicative-decrease algorithm.
+type AIMDConfig struct {
+	InitialLimit int `yaml:"initial_limit"`
+	MinLimit     int `yaml:"min_limit"`
+	MaxLimit     int `yaml:"max_limit"`
+	// BackoffRatio multiplies the limit when a request is dropped or slower than LatencyThreshold.
+	BackoffRatio     float64       `yaml:"backoff_ratio"`
+	LatencyThreshold time.Duration `yaml:"latency_threshold"`
+}
+
+var defaultAIMDConfig = AIMDConfig{
+	InitialLimit:     100,
+	MinLimit:         10,
+	MaxLimit:         1000,
+	BackoffRatio:     0.9,
+	LatencyThreshold: time.Second,
+}
+
+type aimd struct {
+	mu     sync.Mutex
+	config AIMDConfig
+	limit  float64
+}
+
+// NewAIMD grows the limit by one for every fast request while the limit is in use and shrinks it
+// by BackoffRatio on every slow or dropped request. Zero fields use the defaults.
+func NewAIMD(config AIMDConfig) Algorithm {
+	config.InitialLimit = orDefault(config.InitialLimit, defaultAIMDConfig.InitialLimit)
+	config.MinLimit = orDefault(config.MinLimit, defaultAIMDConfig.MinLimit)
+	config.MaxLimit = orDefault(config.MaxLimit, defaultAIMDConfig.MaxLimit)
+	if config.BackoffRatio <= 0 || config.BackoffRatio >= 1 {
+		config.BackoffRatio = defaultAIMDConfig.BackoffRatio
+	}
+	if config.LatencyThreshold <= 0 {
+		config.LatencyThreshold = defaultAIMDConfig.LatencyThreshold
+	}
+	return &aimd{config: config, limit: float64(config.InitialLimit)}
+}
+
+func (a *aimd) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return int(a.limit)
+}
+
+func (a *aimd) OnSample(rtt time.Duration, inflight int, dropped bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch {
+	case dropped || rtt > a.config.LatencyThreshold:
+		a.limit *= a.config.BackoffRatio
+	case float64(inflight)*2 >= a.limit:
+		// only grow when the limit is actually used, otherwise it drifts up while idle
+		a.limit++
+	}
+	a.limit = clamp(a.limit, a.config.MinLimit, a.config.MaxLimit)
+}
+
+// GradientConfig configures the gradient algorithm.
+type GradientConfig struct {
+	InitialLimit int `yaml:"initial_limit"`
+	MinLimit     int `yaml:"min_limit"`
+	MaxLimit     int `yaml:"max_limit"`
+	// Smoothing is the weight of a new limit, in (0, 1].
+	Smoothing float64 `yaml:"smoothing"`
+	// Tolerance is how much the short-term latency may exceed the long-term one before shrinking.
+	Tolerance float64 `yaml:"tolerance"`
+	// LongWindow is the number of samples averaged into the long-term latency.
+	LongWindow int `yaml:"long_window"`
+}
+
+var defaultGradientConfig = GradientConfig{
+	InitialLimit: 100,
+	MinLimit:     10,
+	MaxLimit:     1000,
+	Smoothing:    0.2,
+	Tolerance:    1.5,
+	LongWindow:   600,
+}
+
+type gradient struct {
+	mu      sync.Mutex
+	config  GradientConfig
+	limit   float64
+	longRtt float64
+	samples int
+}
+
+// NewGradient compares the latency of each request with the long-term average latency and
+// shrinks the limit as queueing makes requests slower. Zero fields use the defaults.
+func NewGradient(config GradientConfig) Algorithm {
+	config.InitialLimit = orDefault(config.InitialLimit, defaultGradientConfig.InitialLimit)
+	config.MinLimit = orDefault(config.MinLimit, defaultGradientConfig.MinLimit)
+	config.MaxLimit = orDefault(config.MaxLimit, defaultGradientConfig.MaxLimit)
+	config.LongWindow = orDefault(config.LongWindow, defaultGradientConfig.LongWindow)
+	if config.Smoothing <= 0 || config.Smoothing > 1 {
+		config.Smoothing = defaultGradientConfig.Smoothing
+	}
+	if config.Tolerance < 1 {
+		config.Tolerance = defaultGradientConfig.Tolerance
+	}
+	return &gradient{config: config, limit: float64(config.InitialLimit)}
+}
+
+func (g *gradient) Limit() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return int(g.limit)
+}
+
+func (g *gradient) OnSample(rtt time.Duration, inflight int, dropped bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	shortRtt := float64(rtt)
+	if g.samples < g.config.LongWindow {
+		g.samples++
+	}
+	if g.longRtt == 0 {
+		g.longRtt = shortRtt
+	} else {
+		g.longRtt += (shortRtt - g.longRtt) / float64(g.samples)
+	}
+
+	// the limit is not used, its latency says nothing about a larger one
+	if !dropped && float64(inflight)*2 < g.limit {
+		return
+	}
+
+	grad := 0.5
+	if !dropped && shortRtt > 0 {
+		grad = math.Max(0.5, math.Min(1, g.config.Tolerance*g.longRtt/shortRtt))
+	}
+	queueSize := math.Sqrt(g.limit)
+	newLimit := g.limit*grad + queueSize
+	g.limit = clamp(g.limit*(1-g.config.Smoothing)+newLimit*g.config.Smoothing, g.config.MinLimit, g.config.MaxLimit)
+}
+
+func orDefault(value, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func clamp(limit float64, minLimit, maxLimit int) float64 {
+	return math.Max(float64(minLimit), math.Min(float64(maxLimit), limit))
+}
@@ -0,0 +1,96 @@
+package concurrency
+
+import (
+	"testing"
+	"time"
+)
+
+type sample struct {
+	rtt      time.Duration
+	inflight int
+	dropped  bool
+}
+
+func TestAIMD(t *testing.T) {
+	config := AIMDConfig{InitialLimit: 10, MinLimit: 5, MaxLimit: 12, BackoffRatio: 0.5, LatencyThreshold: 100 * time.Millisecond}
+	fast := sample{rtt: 10 * time.Millisecond, inflight: 6}
+	tests := []struct {
+		name    string
+		samples []sample
+		want    int
+	}{
+		{name: "initial limit", want: 10},
+		{name: "grows by one per fast request", samples: []sample{fast}, want: 11},
+		{name: "capped at max", samples: []sample{fast, fast, fast, fast}, want: 12},
+		{name: "idle limit does not grow", samples: []sample{{rtt: 10 * time.Millisecond, inflight: 1}}, want: 10},
+		{name: "slow request backs off", samples: []sample{fast, fast, {rtt: 200 * time.Millisecond, inflight: 6}}, want: 6},
+		{name: "dropped request backs off", samples: []sample{{rtt: time.Millisecond, inflight: 1, dropped: true}}, want: 5},
+		{name: "floored at min", samples: []sample{{dropped: true}, {dropped: true}, {dropped: true}}, want: 5},
+		{name: "grows back after backoff", samples: []sample{{dropped: true}, {rtt: time.Millisecond, inflight: 3}}, want: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAIMD(config)
+			for _, s := range tt.samples {
+				a.OnSample(s.rtt, s.inflight, s.dropped)
+			}
+			if got := a.Limit(); got != tt.want {
+				t.Errorf("limit = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAIMDDefaults(t *testing.T) {
+	a := NewAIMD(AIMDConfig{BackoffRatio: 2}).(*aimd)
+	if a.config != defaultAIMDConfig {
+		t.Errorf("config = %+v, want the defaults %+v", a.config, defaultAIMDConfig)
+	}
+}
+
+func TestGradient(t *testing.T) {
+	// a smoothing of 1 applies every new limit as is
+	config := GradientConfig{InitialLimit: 100, MinLimit: 10, MaxLimit: 200, Smoothing: 1, Tolerance: 1.5}
+	steady := sample{rtt: 10 * time.Millisecond, inflight: 100}
+	tests := []struct {
+		name    string
+		samples []sample
+		want    int
+	}{
+		{name: "initial limit", want: 100},
+		// limit*1 + sqrt(limit)
+		{name: "grows by its queue size at steady latency", samples: []sample{steady}, want: 110},
+		{name: "keeps growing at steady latency", samples: []sample{steady, steady}, want: 120},
+		{name: "capped at max", samples: []sample{steady, steady, steady, steady, steady, steady, steady, steady, steady, steady}, want: 200},
+		{name: "idle limit does not change", samples: []sample{{rtt: time.Second, inflight: 10}}, want: 100},
+		// gradient 1.5 * 11.25ms / 15ms capped at 1, the tolerance absorbs jitter
+		{name: "tolerates slightly slower requests", samples: []sample{steady, steady, steady, {rtt: 15 * time.Millisecond, inflight: 100}}, want: 142},
+		// long rtt 10ms + 90ms / 2 = 55ms, gradient 1.5 * 55 / 100 = 0.825: 110*0.825 + sqrt(110)
+		{name: "shrinks when requests queue", samples: []sample{steady, {rtt: 100 * time.Millisecond, inflight: 100}}, want: 101},
+		// gradient floored at 0.5: 100*0.5 + sqrt(100)
+		{name: "halves on a dropped request", samples: []sample{{rtt: time.Millisecond, inflight: 1, dropped: true}}, want: 60},
+		{
+			name:    "floored at min",
+			samples: []sample{{dropped: true}, {dropped: true}, {dropped: true}, {dropped: true}, {dropped: true}, {dropped: true}, {dropped: true}},
+			want:    10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGradient(config)
+			for _, s := range tt.samples {
+				g.OnSample(s.rtt, s.inflight, s.dropped)
+			}
+			if got := g.Limit(); got != tt.want {
+				t.Errorf("limit = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGradientDefaults(t *testing.T) {
+	g := NewGradient(GradientConfig{Smoothing: 2, Tolerance: 0.5}).(*gradient)
+	if g.config != defaultGradientConfig {
+		t.Errorf("config = %+v, want the defaults %+v", g.config, defaultGradientConfig)
+	}
+}
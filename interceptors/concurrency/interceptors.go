@@ -0,0 +1,110 @@
+package concurrency
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// overloadCodes mark a request as dropped because the server is overloaded.
+var overloadCodes = map[codes.Code]struct{}{
+	codes.ResourceExhausted: {},
+	codes.DeadlineExceeded:  {},
+	codes.Unavailable:       {},
+}
+
+func errLimitExceeded() error {
+	return status.Error(codes.ResourceExhausted, "concurrency limit exceeded")
+}
+
+func droppedByCode(err error) bool {
+	_, ok := overloadCodes[status.Code(err)]
+	return ok
+}
+
+// Limit returns a new unary server interceptor that rejects requests with codes.ResourceExhausted
+// once the adaptive concurrency limit is reached, instead of queueing them.
+func Limit(opts ...Option) grpc.UnaryServerInterceptor {
+	o := evaluateOptions(opts)
+	l := newLimiters(o)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := o.exempt[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+		lim := l.get(info.FullMethod)
+		t, ok := lim.acquire()
+		if !ok {
+			ctxzap.AddFields(ctx, zap.Bool("load_shed", true), zap.Int("concurrency_limit", lim.algorithm.Limit()))
+			return nil, errLimitExceeded()
+		}
+
+		// a panicking handler still releases its slot
+		dropped := true
+		defer func() { t.release(dropped) }()
+
+		resp, err := handler(ctx, req)
+		dropped = droppedByCode(err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new streaming server interceptor limiting concurrent streams.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := evaluateOptions(opts)
+	l := newLimiters(o)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := o.exempt[info.FullMethod]; ok {
+			return handler(srv, stream)
+		}
+		t, ok := l.get(info.FullMethod).acquire()
+		if !ok {
+			return errLimitExceeded()
+		}
+
+		dropped := true
+		defer func() { t.release(dropped) }()
+
+		err := handler(srv, stream)
+		dropped = droppedByCode(err)
+		return err
+	}
+}
+
+// HttpLimit is a Gin middleware that rejects requests with 429 Too Many Requests
+// once the adaptive concurrency limit is reached.
+func HttpLimit(opts ...Option) gin.HandlerFunc {
+	o := evaluateOptions(opts)
+	l := newLimiters(o)
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		if _, ok := o.exempt[route]; ok {
+			c.Next()
+			return
+		}
+		lim := l.get(route)
+		t, ok := lim.acquire()
+		if !ok {
+			interceptors.AddHttpLogFields(c, zap.Bool("load_shed", true), zap.Int("concurrency_limit", lim.algorithm.Limit()))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too many requests",
+			})
+			return
+		}
+
+		dropped := true
+		defer func() { t.release(dropped) }()
+
+		c.Next()
+		statusCode := c.Writer.Status()
+		dropped = statusCode == http.StatusTooManyRequests ||
+			statusCode == http.StatusServiceUnavailable ||
+			statusCode == http.StatusGatewayTimeout
+	}
+}
@@ -0,0 +1,176 @@
+package concurrency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testMethod = "/order.OrderService/GetOrder"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fixedLimit never adapts its limit and records the samples it gets.
+type fixedLimit struct {
+	mu      sync.Mutex
+	limit   int
+	dropped []bool
+}
+
+func (f *fixedLimit) Limit() int {
+	return f.limit
+}
+
+func (f *fixedLimit) OnSample(_ time.Duration, _ int, dropped bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dropped = append(f.dropped, dropped)
+}
+
+func (f *fixedLimit) samples() []bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]bool(nil), f.dropped...)
+}
+
+func withFixedLimit(algorithm *fixedLimit) Option {
+	return WithAlgorithm(func() Algorithm { return algorithm })
+}
+
+func TestLimitShedsOverLimit(t *testing.T) {
+	algorithm := &fixedLimit{limit: 1}
+	interceptor := Limit(withFixedLimit(algorithm))
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				close(started)
+				<-release
+				return nil, nil
+			})
+		done <- err
+	}()
+	<-started
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("code = %s, want ResourceExhausted", status.Code(err))
+	}
+	if _, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler); err != nil {
+		t.Errorf("health check shed: %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler); err != nil {
+		t.Errorf("call after release: %v", err)
+	}
+	if got := algorithm.samples(); len(got) != 2 || got[0] || got[1] {
+		t.Errorf("samples = %v, want two successful ones, shed requests are not sampled", got)
+	}
+}
+
+func TestLimitSamplesOverloadAsDropped(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "success", want: false},
+		{name: "not found", err: status.Error(codes.NotFound, "not found"), want: false},
+		{name: "unavailable", err: status.Error(codes.Unavailable, "unavailable"), want: true},
+		{name: "deadline exceeded", err: status.Error(codes.DeadlineExceeded, "deadline"), want: true},
+		{name: "resource exhausted", err: status.Error(codes.ResourceExhausted, "exhausted"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm := &fixedLimit{limit: 1}
+			_, _ = Limit(withFixedLimit(algorithm))(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return nil, tt.err
+				})
+			if got := algorithm.samples(); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("samples = %v, want [%t]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitReleasesOnPanic(t *testing.T) {
+	algorithm := &fixedLimit{limit: 1}
+	interceptor := Limit(withFixedLimit(algorithm))
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				panic("boom")
+			})
+	}()
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	if err != nil {
+		t.Errorf("call after a panic: %v", err)
+	}
+}
+
+func TestHttpLimitShedsOverLimit(t *testing.T) {
+	algorithm := &fixedLimit{limit: 1}
+	started, release := make(chan struct{}), make(chan struct{})
+	r := gin.New()
+	r.Use(HttpLimit(withFixedLimit(algorithm), WithPerMethod()))
+	r.GET("/orders", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+	for _, path := range []string{"/customers", "/healthz", "/readyz"} {
+		r.GET(path, func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	}
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+		done <- w.Code
+	}()
+	<-started
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/orders", want: http.StatusTooManyRequests},
+		// every route has its own limit
+		{path: "/customers", want: http.StatusOK},
+		{path: "/healthz", want: http.StatusOK},
+		{path: "/readyz", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.path, w.Code, tt.want)
+		}
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("in flight request status = %d, want 200", code)
+	}
+}
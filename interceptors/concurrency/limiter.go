@@ -0,0 +1,62 @@
+package concurrency
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter rejects requests once the number in flight reaches the limit of its algorithm.
+type limiter struct {
+	mu        sync.Mutex
+	inflight  int
+	algorithm Algorithm
+}
+
+type token struct {
+	limiter  *limiter
+	start    time.Time
+	inflight int
+}
+
+func (l *limiter) acquire() (*token, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight >= l.algorithm.Limit() {
+		return nil, false
+	}
+	l.inflight++
+	return &token{limiter: l, start: time.Now(), inflight: l.inflight}, true
+}
+
+func (t *token) release(dropped bool) {
+	t.limiter.mu.Lock()
+	t.limiter.inflight--
+	t.limiter.mu.Unlock()
+	t.limiter.algorithm.OnSample(time.Since(t.start), t.inflight, dropped)
+}
+
+// limiters holds either one global limiter or one limiter per method.
+type limiters struct {
+	global    *limiter
+	perMethod sync.Map
+	newAlgo   func() Algorithm
+}
+
+func newLimiters(o *options) *limiters {
+	l := &limiters{newAlgo: o.newAlgorithm}
+	if !o.perMethod {
+		l.global = &limiter{algorithm: o.newAlgorithm()}
+	}
+	return l
+}
+
+func (l *limiters) get(method string) *limiter {
+	if l.global != nil {
+		return l.global
+	}
+	if existing, ok := l.perMethod.Load(method); ok {
+		return existing.(*limiter)
+	}
+	existing, _ := l.perMethod.LoadOrStore(method, &limiter{algorithm: l.newAlgo()})
+	return existing.(*limiter)
+}
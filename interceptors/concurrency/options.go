@@ -0,0 +1,86 @@
+package concurrency
+
+var (
+	defaultOptions = &options{
+		newAlgorithm: func() Algorithm {
+			return NewGradient(GradientConfig{})
+		},
+		exempt: map[string]struct{}{
+			"/grpc.health.v1.Health/Check":                                   {},
+			"/grpc.health.v1.Health/Watch":                                   {},
+			"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      {},
+			"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": {},
+			// liveness and readiness probes must not fail because the server is busy
+			"GET /health":  {},
+			"GET /healthz": {},
+			"GET /livez":   {},
+			"GET /readyz":  {},
+			"GET /metrics": {},
+		},
+	}
+)
+
+type options struct {
+	newAlgorithm func() Algorithm
+	perMethod    bool
+	exempt       map[string]struct{}
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	optCopy.exempt = make(map[string]struct{}, len(defaultOptions.exempt))
+	for method := range defaultOptions.exempt {
+		optCopy.exempt[method] = struct{}{}
+	}
+	for _, o := range opts {
+		o(optCopy)
+	}
+	return optCopy
+}
+
+type Option func(*options)
+
+// WithAIMD limits concurrency with the AIMD algorithm.
+func WithAIMD(config AIMDConfig) Option {
+	return func(o *options) {
+		o.newAlgorithm = func() Algorithm {
+			return NewAIMD(config)
+		}
+	}
+}
+
+// WithGradient limits concurrency with the gradient algorithm, it is the default.
+func WithGradient(config GradientConfig) Option {
+	return func(o *options) {
+		o.newAlgorithm = func() Algorithm {
+			return NewGradient(config)
+		}
+	}
+}
+
+// WithAlgorithm uses a custom algorithm, newAlgorithm is called once per limiter.
+func WithAlgorithm(newAlgorithm func() Algorithm) Option {
+	return func(o *options) {
+		o.newAlgorithm = newAlgorithm
+	}
+}
+
+// WithPerMethod keeps a separate limit for every method or route instead of a global one.
+func WithPerMethod() Option {
+	return func(o *options) {
+		o.perMethod = true
+	}
+}
+
+// WithExemptMethods never limits the given gRPC methods or gin routes ("GET /health").
+// The gRPC health checks and reflection, the "GET /health", "GET /healthz", "GET /livez" and
+// "GET /readyz" routes and "GET /metrics" are exempt by default. Exempt the path given to
+// Server.HealthCheck when it is another one, or a shed probe restarts the overloaded server.
+func WithExemptMethods(methods ...string) Option {
+	return func(o *options) {
+		for _, method := range methods {
+			o.exempt[method] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen/utilities"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceparentHeader is the W3C trace context header. Listed in WithCorrelationHeaders,
+// the trace ID of its value is used as the correlation ID.
+const TraceparentHeader = "traceparent"
+
+// maxCorrelationIdLength bounds the accepted correlation IDs.
+const maxCorrelationIdLength = 128
+
+type correlationOptions struct {
+	headers        []string
+	outboundHeader string
+	generator      func(ctx context.Context) string
+	validator      func(id string) bool
+}
+
+type CorrelationOption func(*correlationOptions)
+
+func evaluateCorrelationOpt(opts []CorrelationOption) *correlationOptions {
+	o := &correlationOptions{
+		headers:        []string{common.CorrelationIdKey},
+		outboundHeader: common.CorrelationIdKey,
+		generator:      DefaultCorrelationIdGenerator,
+		validator:      DefaultCorrelationIdValidator,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithCorrelationHeaders sets the ordered header or metadata names the correlation ID is read from,
+// the first valid one wins. Defaults to "correlation_id".
+//
+// Example:
+//
+//	interceptors.WithCorrelationHeaders("X-Request-ID", "X-Correlation-ID", interceptors.TraceparentHeader)
+func WithCorrelationHeaders(names ...string) CorrelationOption {
+	return func(o *correlationOptions) {
+		o.headers = names
+	}
+}
+
+// WithCorrelationOutboundHeader sets the response header or metadata name the correlation ID is sent back in,
+// "correlation_id" by default. An empty name does not send it.
+func WithCorrelationOutboundHeader(name string) CorrelationOption {
+	return func(o *correlationOptions) {
+		o.outboundHeader = name
+	}
+}
+
+// WithCorrelationIdGenerator sets the function generating the correlation ID of requests that have none,
+// DefaultCorrelationIdGenerator by default.
+func WithCorrelationIdGenerator(generator func(ctx context.Context) string) CorrelationOption {
+	return func(o *correlationOptions) {
+		o.generator = generator
+	}
+}
+
+// WithCorrelationIdValidator sets the function accepting inbound correlation IDs, DefaultCorrelationIdValidator
+// by default. Rejected IDs are ignored.
+func WithCorrelationIdValidator(validator func(id string) bool) CorrelationOption {
+	return func(o *correlationOptions) {
+		o.validator = validator
+	}
+}
+
+// DefaultCorrelationIdGenerator returns the trace ID of ctx, or a new UUID when ctx is not traced.
+func DefaultCorrelationIdGenerator(ctx context.Context) string {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+	return utilities.GenerateUUIDV7()
+}
+
+// DefaultCorrelationIdValidator accepts IDs of up to 128 letters, digits and "-", "_", ".", ":",
+// so that they cannot inject headers or log lines.
+func DefaultCorrelationIdValidator(id string) bool {
+	if id == "" || len(id) > maxCorrelationIdLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// correlationId returns the first valid correlation ID read with get from the inbound headers,
+// or a generated one.
+func (o *correlationOptions) correlationId(ctx context.Context, get func(name string) string) string {
+	for _, name := range o.headers {
+		id := get(name)
+		if strings.EqualFold(name, TraceparentHeader) {
+			id = traceIdFromTraceparent(id)
+		}
+		if id != "" && o.validator(id) {
+			return id
+		}
+	}
+	return o.generator(ctx)
+}
+
+// traceIdFromTraceparent returns the trace ID of a "version-traceid-parentid-flags" traceparent value.
+func traceIdFromTraceparent(value string) string {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	traceId, err := trace.TraceIDFromHex(parts[1])
+	if err != nil || !traceId.IsValid() {
+		return ""
+	}
+	return traceId.String()
+}
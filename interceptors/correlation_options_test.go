@@ -0,0 +1,138 @@
+package interceptors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+)
+
+const testTraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+func TestDefaultCorrelationIdValidator(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "uuid", id: "0190a6f5-3c2e-7d4b-9a1f-2b3c4d5e6f70", want: true},
+		{name: "allowed punctuation", id: "svc.order:42_a-b", want: true},
+		{name: "max length", id: strings.Repeat("a", maxCorrelationIdLength), want: true},
+		{name: "empty", id: "", want: false},
+		{name: "over max length", id: strings.Repeat("a", maxCorrelationIdLength+1), want: false},
+		{name: "carriage return", id: "abc\rdef", want: false},
+		{name: "line feed", id: "abc\ndef", want: false},
+		{name: "header injection", id: "abc\r\nSet-Cookie: x=1", want: false},
+		{name: "space", id: "abc def", want: false},
+		{name: "non ascii", id: "abcé", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultCorrelationIdValidator(tt.id); got != tt.want {
+				t.Errorf("DefaultCorrelationIdValidator(%q) = %t, want %t", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceIdFromTraceparent(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "valid", value: "00-" + testTraceId + "-00f067aa0ba902b7-01", want: testTraceId},
+		{name: "surrounding spaces", value: " 00-" + testTraceId + "-00f067aa0ba902b7-01 ", want: testTraceId},
+		{name: "uppercase trace id", value: "00-" + strings.ToUpper(testTraceId) + "-00f067aa0ba902b7-01", want: ""},
+		{name: "empty", value: "", want: ""},
+		{name: "missing flags", value: "00-" + testTraceId + "-00f067aa0ba902b7", want: ""},
+		{name: "short trace id", value: "00-4bf92f3577b34da6-00f067aa0ba902b7-01", want: ""},
+		{name: "non hex trace id", value: "00-" + strings.Repeat("z", 32) + "-00f067aa0ba902b7-01", want: ""},
+		{name: "all zero trace id", value: "00-" + strings.Repeat("0", 32) + "-00f067aa0ba902b7-01", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := traceIdFromTraceparent(tt.value); got != tt.want {
+				t.Errorf("traceIdFromTraceparent(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorrelationId(t *testing.T) {
+	headers := []CorrelationOption{WithCorrelationHeaders("X-Request-ID", TraceparentHeader)}
+	generated := WithCorrelationIdGenerator(func(ctx context.Context) string { return "generated" })
+	tests := []struct {
+		name    string
+		opts    []CorrelationOption
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "first header wins",
+			headers: map[string]string{"X-Request-ID": "req-1", TraceparentHeader: "00-" + testTraceId + "-00f067aa0ba902b7-01"},
+			want:    "req-1",
+		},
+		{
+			name:    "trace id of traceparent",
+			headers: map[string]string{TraceparentHeader: "00-" + testTraceId + "-00f067aa0ba902b7-01"},
+			want:    testTraceId,
+		},
+		{
+			name:    "invalid header skipped",
+			headers: map[string]string{"X-Request-ID": "req\r\n1", TraceparentHeader: "00-" + testTraceId + "-00f067aa0ba902b7-01"},
+			want:    testTraceId,
+		},
+		{
+			name:    "overlong header skipped",
+			headers: map[string]string{"X-Request-ID": strings.Repeat("a", maxCorrelationIdLength+1)},
+			want:    "generated",
+		},
+		{
+			name:    "invalid traceparent generates",
+			headers: map[string]string{TraceparentHeader: "garbage"},
+			want:    "generated",
+		},
+		{
+			name:    "custom validator",
+			opts:    []CorrelationOption{WithCorrelationIdValidator(func(id string) bool { return strings.HasPrefix(id, "req-") })},
+			headers: map[string]string{"X-Request-ID": "other"},
+			want:    "generated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := evaluateCorrelationOpt(append(append(headers, generated), tt.opts...))
+			got := o.correlationId(context.Background(), func(name string) string { return tt.headers[name] })
+			if got != tt.want {
+				t.Errorf("correlation id = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHttpCorrelationTracingRejectsInjectedId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(HttpCorrelationTracing())
+	var correlationId string
+	r.GET("/", func(c *gin.Context) {
+		correlationId = reqctx.CorrelationId(c.Request.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header[http.CanonicalHeaderKey(common.CorrelationIdKey)] = []string{"abc\r\nX-Injected: 1"}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if correlationId == "" || strings.ContainsAny(correlationId, "\r\n") {
+		t.Fatalf("correlation id = %q, want a generated one", correlationId)
+	}
+	if got := w.Header().Get(common.CorrelationIdKey); got != correlationId {
+		t.Errorf("response header = %q, want %q", got, correlationId)
+	}
+}
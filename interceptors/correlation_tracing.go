@@ -2,24 +2,51 @@ package interceptors
 
 import (
 	"context"
+	"net"
+	"strings"
 
 	"github.com/tqhuy-dev/xgen-uranus/common"
-	"github.com/tqhuy-dev/xgen/utilities"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
-func CorrelationTracing() grpc.UnaryServerInterceptor {
+// CorrelationTracing puts the correlation ID of the metadata in the context and sends it back in the
+// response header metadata, and populates the reqctx.RequestMeta of the call. When none is supplied
+// it reuses the trace ID of the request, or generates one when the request is not traced, see
+// WithCorrelationHeaders and WithCorrelationIdGenerator.
+func CorrelationTracing(opts ...CorrelationOption) grpc.UnaryServerInterceptor {
+	o := evaluateCorrelationOpt(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		correlationId := utilities.GenerateUUIDV7()
+		md, _ := metadata.FromIncomingContext(ctx)
+		correlationId := o.correlationId(ctx, func(name string) string {
+			if values := md.Get(name); len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		})
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if ok {
-			if len(md[common.CorrelationIdKey]) > 0 && len(md[common.CorrelationIdKey][0]) > 0 {
-				correlationId = md[common.CorrelationIdKey][0]
+		ctx = context.WithValue(ctx, common.CorrelationIdKey, correlationId)
+		ctx = reqctx.Update(ctx, func(meta *reqctx.RequestMeta) {
+			meta.CorrelationId = correlationId
+			meta.Route = info.FullMethod
+			if values := md.Get("user-agent"); len(values) > 0 {
+				meta.UserAgent = values[0]
 			}
+			if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+				meta.ClientIP = p.Addr.String()
+				if host, _, err := net.SplitHostPort(meta.ClientIP); err == nil {
+					meta.ClientIP = host
+				}
+			}
+		})
+		if o.outboundHeader != "" {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(o.outboundHeader), correlationId))
 		}
-		ctx = context.WithValue(ctx, common.CorrelationIdKey, correlationId)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String(common.CorrelationIdKey, correlationId))
 		resp, err := handler(ctx, req)
 		return resp, err
 	}
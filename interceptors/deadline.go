@@ -0,0 +1,77 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const (
+	deadlineMissing = "missing"
+	deadlineTooLong = "too_long"
+)
+
+// DeadlineConfig bounds how long a handler may run.
+// Methods overrides Default by full gRPC method ("/pkg.Service/Method") or by gin route ("GET /users/:id").
+type DeadlineConfig struct {
+	Default time.Duration            `yaml:"default"`
+	Methods map[string]time.Duration `yaml:"methods"`
+}
+
+func (c DeadlineConfig) timeoutFor(method string) time.Duration {
+	if timeout, ok := c.Methods[method]; ok {
+		return timeout
+	}
+	return c.Default
+}
+
+// applyDeadline shortens ctx to timeout when it has no deadline or a later one,
+// and returns which of the two happened.
+func applyDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, string) {
+	if timeout <= 0 {
+		return ctx, func() {}, ""
+	}
+	violation := deadlineMissing
+	if deadline, ok := ctx.Deadline(); ok {
+		if time.Until(deadline) <= timeout {
+			return ctx, func() {}, ""
+		}
+		violation = deadlineTooLong
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, violation
+}
+
+func deadlineFields(violation string, timeout time.Duration, exceeded bool) []zap.Field {
+	var fields []zap.Field
+	if violation != "" {
+		fields = append(fields,
+			zap.String("deadline_violation", violation),
+			zap.Duration("deadline_applied", timeout))
+	}
+	if exceeded {
+		fields = append(fields, zap.Bool("deadline_exceeded", true))
+	}
+	return fields
+}
+
+// Deadline applies the configured timeout when the incoming deadline is missing or too long.
+// Place it after ZapLogInterceptor so that violations are reported in the request log.
+func Deadline(config DeadlineConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		timeout := config.timeoutFor(info.FullMethod)
+		newCtx, cancel, violation := applyDeadline(ctx, timeout)
+		defer cancel()
+
+		resp, err := handler(newCtx, req)
+		exceeded := errors.Is(newCtx.Err(), context.DeadlineExceeded)
+		if fields := deadlineFields(violation, timeout, exceeded); len(fields) > 0 {
+			ctxzap.AddFields(ctx, fields...)
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,57 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	uranuserrors "github.com/tqhuy-dev/xgen-uranus/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// internalReason is the reason of the errors that are hidden from clients.
+const internalReason = "INTERNAL"
+
+// mapError converts err to an error safe to send to clients. *errors.Error and gRPC status errors are kept,
+// context errors become cancellation and timeout errors and any other error an internal error without
+// its message, which may leak implementation details. internal reports whether err was hidden.
+func mapError(err error) (mapped *uranuserrors.Error, internal bool) {
+	var e *uranuserrors.Error
+	if errors.As(err, &e) {
+		return e, false
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		return uranuserrors.FromError(err), false
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return uranuserrors.New(uranuserrors.ClientClosed, "CANCELED", "request canceled").WithCause(err), false
+	case errors.Is(err, context.DeadlineExceeded):
+		return uranuserrors.GatewayTimeout("DEADLINE_EXCEEDED", "request deadline exceeded").WithCause(err), false
+	}
+	return uranuserrors.InternalServer(internalReason, "internal error").WithCause(err), true
+}
+
+// ErrorMapping returns a new unary server interceptor that converts the errors of handlers to gRPC statuses.
+// *errors.Error carry their reason and metadata in an ErrorInfo detail, and errors that are neither an
+// *errors.Error nor a gRPC status are hidden behind a codes.Internal status, their message is logged
+// in the "internal_error" field instead.
+func ErrorMapping() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if _, ok := status.FromError(err); ok && status.Code(err) != codes.Unknown {
+			return resp, err
+		}
+		mapped, internal := mapError(err)
+		if internal {
+			ctxzap.AddFields(ctx, zap.String("internal_error", err.Error()))
+		}
+		return resp, mapped.GRPCStatus().Err()
+	}
+}
@@ -1,21 +1,37 @@
 package interceptors
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/tqhuy-dev/xgen-uranus/common"
-	"github.com/tqhuy-dev/xgen/utilities"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// HttpCorrelationTracing is a Gin middleware that adds correlation ID to the context
-func HttpCorrelationTracing() gin.HandlerFunc {
+// HttpCorrelationTracing is a Gin middleware that adds correlation ID to the context and the response headers.
+// It also populates the reqctx.RequestMeta of the request.
+// When none is supplied it reuses the trace ID of the request, or generates one when the request is not traced,
+// see WithCorrelationHeaders and WithCorrelationIdGenerator.
+func HttpCorrelationTracing(opts ...CorrelationOption) gin.HandlerFunc {
+	o := evaluateCorrelationOpt(opts)
 	return func(c *gin.Context) {
-		correlationId := c.GetHeader(common.CorrelationIdKey)
-		if correlationId == "" {
-			correlationId = utilities.GenerateUUIDV7()
-		}
+		correlationId := o.correlationId(c.Request.Context(), c.GetHeader)
 
 		c.Set(common.CorrelationIdKey, correlationId)
-		c.Header(common.CorrelationIdKey, correlationId)
+		ctx := context.WithValue(c.Request.Context(), common.CorrelationIdKey, correlationId)
+		ctx = reqctx.Update(ctx, func(meta *reqctx.RequestMeta) {
+			meta.CorrelationId = correlationId
+			meta.ClientIP = c.ClientIP()
+			meta.UserAgent = c.Request.UserAgent()
+			meta.Route = c.Request.Method + " " + c.FullPath()
+		})
+		c.Request = c.Request.WithContext(ctx)
+		if o.outboundHeader != "" {
+			c.Header(o.outboundHeader, correlationId)
+		}
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String(common.CorrelationIdKey, correlationId))
 
 		c.Next()
 	}
@@ -0,0 +1,31 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HttpDeadline is a Gin middleware that applies the configured timeout to the request context
+// when its deadline is missing or too long. Handlers must honor c.Request.Context().
+// Place it after HttpZapLogMiddleware so that violations are reported in the request log.
+func HttpDeadline(config DeadlineConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := config.timeoutFor(c.Request.Method + " " + c.FullPath())
+		ctx, cancel, violation := applyDeadline(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		exceeded := errors.Is(ctx.Err(), context.DeadlineExceeded)
+		if exceeded && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+				"error": "Deadline exceeded",
+			})
+		}
+		AddHttpLogFields(c, deadlineFields(violation, timeout, exceeded)...)
+	}
+}
@@ -0,0 +1,32 @@
+package interceptors
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HttpErrorMapping is a Gin middleware that renders the last error added with c.Error as the JSON body
+// of errors.HttpBody, when the handler has not written a response. Errors that are not an *errors.Error
+// are hidden behind a 500 response, their message is logged in the "internal_error" field instead.
+//
+// Usage in handler:
+//
+//	if err != nil {
+//	    _ = c.Error(err)
+//	    return
+//	}
+func HttpErrorMapping() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+		err := c.Errors.Last().Err
+		mapped, internal := mapError(err)
+		if internal {
+			AddHttpLogFields(c, zap.String("internal_error", err.Error()))
+		}
+		c.AbortWithStatusJSON(int(mapped.Code), mapped.ToHttpBody())
+	}
+}
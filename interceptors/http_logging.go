@@ -1,15 +1,112 @@
 package interceptors
 
 import (
+	"bytes"
+	"io"
+	"reflect"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen-uranus/logging"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
+// httpLogFieldsKey stores the fields added by other middlewares for the request log
+const httpLogFieldsKey = "uranus.http_log_fields"
+
+// AddHttpLogFields adds fields to the log line written by HttpZapLogMiddleware for this request.
+func AddHttpLogFields(c *gin.Context, fields ...zap.Field) {
+	if len(fields) == 0 {
+		return
+	}
+	existing, _ := c.Get(httpLogFieldsKey)
+	current, _ := existing.([]zap.Field)
+	c.Set(httpLogFieldsKey, append(current, fields...))
+}
+
 type httpLoggingOptions struct {
-	appName string
+	appName       string
+	payload       payloadLogging
+	skipPaths     map[string]struct{}
+	levelFunc     StatusToLevel
+	durationFunc  DurationToField
+	messageFunc   HttpMessageProducer
+	sampler       *requestSampler
+	slowThreshold time.Duration
+}
+
+// StatusToLevel maps an HTTP status code to the level of the request log.
+type StatusToLevel func(status int) zapcore.Level
+
+// HttpMessageProducer writes the request log.
+type HttpMessageProducer func(c *gin.Context, logger *zap.Logger, msg string, level zapcore.Level, fields []zap.Field)
+
+// DefaultStatusToLevel logs server errors as errors, client errors as warnings and other requests as info.
+func DefaultStatusToLevel(status int) zapcore.Level {
+	switch {
+	case status >= 500:
+		return zap.ErrorLevel
+	case status >= 400:
+		return zap.WarnLevel
+	default:
+		return zap.InfoLevel
+	}
+}
+
+// DefaultHttpMessageProducer writes the request log with fields at level.
+func DefaultHttpMessageProducer(c *gin.Context, logger *zap.Logger, msg string, level zapcore.Level, fields []zap.Field) {
+	logger.Check(level, msg).Write(fields...)
+}
+
+// WithHttpSkipPaths does not log the successful requests to the given paths, matched against the
+// request path ("/healthz") and the route template ("/users/:id"). Failed requests are always logged.
+func WithHttpSkipPaths(paths ...string) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		for _, p := range paths {
+			o.skipPaths[p] = struct{}{}
+		}
+	}
+}
+
+// WithHttpLevels customizes the function for mapping HTTP status codes to log levels, DefaultStatusToLevel by default.
+func WithHttpLevels(f StatusToLevel) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		o.levelFunc = f
+	}
+}
+
+// WithHttpDurationField customizes the function for mapping request durations to Zap fields,
+// DurationToTimeMillisField by default.
+func WithHttpDurationField(f DurationToField) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		o.durationFunc = f
+	}
+}
+
+// WithHttpMessageProducer customizes the function for message formation, DefaultHttpMessageProducer by default.
+func WithHttpMessageProducer(f HttpMessageProducer) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		o.messageFunc = f
+	}
+}
+
+// WithHttpSampling samples the logs of successful requests per route with config, see zap.SamplingConfig.
+// Failed requests and requests slower than the threshold of WithHttpSlowThreshold are always logged.
+func WithHttpSampling(config zap.SamplingConfig) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		o.sampler = newRequestSampler(config)
+	}
+}
+
+// WithHttpSlowThreshold always logs the requests lasting longer than threshold with the field slow, even
+// when they are sampled out or their path is skipped.
+func WithHttpSlowThreshold(threshold time.Duration) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		o.slowThreshold = threshold
+	}
 }
 
 type HttpLoggingOption func(*httpLoggingOptions)
@@ -20,9 +117,80 @@ func WithHttpAppName(appName string) HttpLoggingOption {
 	}
 }
 
+// WithHttpPayloadLogging logs the JSON or form request and response bodies of the given gin routes
+// ("POST /users"), of every route when none is given. Sensitive fields are redacted, see WithHttpRedactedFields.
+func WithHttpPayloadLogging(routes ...string) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		o.payload.enable(routes)
+	}
+}
+
+// WithHttpPayloadMaxBytes caps the size of logged bodies, 4KB by default.
+func WithHttpPayloadMaxBytes(maxBytes int) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		o.payload.maxBytes = maxBytes
+	}
+}
+
+// WithHttpRedactedFields masks more fields in logged bodies, in addition to DefaultRedactedFields.
+func WithHttpRedactedFields(names ...string) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		o.payload.redact(names...)
+	}
+}
+
+// WithHttpRedactedStructs masks the JSON fields of the structs tagged `redact:"true"` in logged bodies.
+func WithHttpRedactedStructs(structs ...interface{}) HttpLoggingOption {
+	return func(o *httpLoggingOptions) {
+		for _, v := range structs {
+			for name := range structRedactedFields(reflect.TypeOf(v)) {
+				o.payload.redact(name)
+			}
+		}
+	}
+}
+
+// payloadCaptureBytes caps the size of captured requests and responses. Bodies are redacted before being
+// truncated to the logged size, so larger JSON bodies cannot be parsed and are not logged.
+const payloadCaptureBytes = 1 << 20
+
+// payloadWriter copies the beginning of the response body while it is written.
+type payloadWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *payloadWriter) capture(b []byte) {
+	if remaining := payloadCaptureBytes + 1 - w.body.Len(); remaining > 0 {
+		w.body.Write(b[:min(len(b), remaining)])
+	}
+}
+
+func (w *payloadWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *payloadWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// replayedBody reads the captured beginning of a request body again before the rest of it.
+type replayedBody struct {
+	io.Reader
+	io.Closer
+}
+
 // HttpZapLogMiddleware is a Gin middleware that logs HTTP requests using zap
 func HttpZapLogMiddleware(logger *zap.Logger, opts ...HttpLoggingOption) gin.HandlerFunc {
-	o := &httpLoggingOptions{}
+	o := &httpLoggingOptions{
+		payload:      newPayloadLogging(),
+		skipPaths:    make(map[string]struct{}),
+		levelFunc:    DefaultStatusToLevel,
+		durationFunc: DefaultDurationToField,
+		messageFunc:  DefaultHttpMessageProducer,
+	}
 	for _, opt := range opts {
 		opt(o)
 	}
@@ -33,42 +201,91 @@ func HttpZapLogMiddleware(logger *zap.Logger, opts ...HttpLoggingOption) gin.Han
 		query := c.Request.URL.RawQuery
 		method := c.Request.Method
 
+		route := c.FullPath()
+		c.Request = c.Request.WithContext(logging.ToContext(c.Request.Context(), logger.With(
+			zap.String("method", method),
+			zap.String("path", path),
+			zap.String("route", route),
+			zap.String(common.LogKeyAppName, o.appName),
+		)))
+
+		var requestPayload string
+		var writer *payloadWriter
+		if o.payload.applies(method + " " + route) {
+			if c.Request.Body != nil {
+				// read no more than the captured size, the handler gets the rest of the body as it streams
+				body, _ := io.ReadAll(io.LimitReader(c.Request.Body, payloadCaptureBytes+1))
+				c.Request.Body = replayedBody{Reader: io.MultiReader(bytes.NewReader(body), c.Request.Body), Closer: c.Request.Body}
+				if len(body) <= payloadCaptureBytes {
+					requestPayload = o.payload.renderBody(c.ContentType(), body)
+				}
+			}
+			writer = &payloadWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+			c.Writer = writer
+		}
+
 		// Process request
 		c.Next()
 
 		// After request - log the response
 		duration := time.Since(startTime)
 		statusCode := c.Writer.Status()
-		correlationId, _ := c.Get(common.CorrelationIdKey)
-		correlationIdStr, _ := correlationId.(string)
+		level := o.levelFunc(statusCode)
+		const msg = "HTTP request completed"
+		slow := o.slowThreshold > 0 && duration > o.slowThreshold
+		failed := statusCode >= 400 || len(c.Errors) > 0
+		if !slow && !failed {
+			if o.skipped(path, route) {
+				return
+			}
+			if o.sampler != nil && !o.sampler.sample(method+" "+route, msg, level) {
+				return
+			}
+		}
+		correlationIdStr := reqctx.CorrelationId(c)
 
 		fields := []zap.Field{
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.Int("status", statusCode),
-			zap.Float32("duration_ms", float32(duration.Nanoseconds()/1000)/1000),
+			o.durationFunc(duration),
 			zap.String("app_name", o.appName),
 			zap.String("correlation_id", correlationIdStr),
 		}
 
+		fields = append(fields, TraceFields(c.Request.Context())...)
+
 		if query != "" {
 			fields = append(fields, zap.String("query", query))
 		}
 
+		if writer != nil {
+			fields = append(fields,
+				zap.String("request_payload", requestPayload),
+				zap.String("response_payload", o.payload.renderBody(writer.Header().Get("Content-Type"), writer.body.Bytes())))
+		}
+
+		if extra, ok := c.Get(httpLogFieldsKey); ok {
+			fields = append(fields, extra.([]zap.Field)...)
+		}
+
+		if slow {
+			fields = append(fields, zap.Bool("slow", true))
+		}
+
 		// Log errors if any
 		if len(c.Errors) > 0 {
 			fields = append(fields, zap.String("error", c.Errors.String()))
 		}
 
-		// Determine log level based on status code
-		msg := "HTTP request completed"
-		switch {
-		case statusCode >= 500:
-			logger.Error(msg, fields...)
-		case statusCode >= 400:
-			logger.Warn(msg, fields...)
-		default:
-			logger.Info(msg, fields...)
-		}
+		o.messageFunc(c, logger, msg, level, fields)
+	}
+}
+
+func (o *httpLoggingOptions) skipped(path string, route string) bool {
+	if _, ok := o.skipPaths[path]; ok {
+		return true
 	}
+	_, ok := o.skipPaths[route]
+	return ok && route != ""
 }
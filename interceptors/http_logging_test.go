@@ -0,0 +1,52 @@
+package interceptors
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHttpZapLogMiddlewareRequestPayload(t *testing.T) {
+	large := `{"name":"` + strings.Repeat("a", payloadCaptureBytes) + `"}`
+	tests := []struct {
+		name        string
+		body        string
+		wantPayload string
+	}{
+		{name: "small body", body: `{"name":"alice","password":"secret"}`, wantPayload: `{"name":"alice","password":"` + RedactedValue + `"}`},
+		{name: "body over the capture size", body: large, wantPayload: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			core, logs := observer.New(zapcore.InfoLevel)
+			r := gin.New()
+			r.Use(HttpZapLogMiddleware(zap.New(core), WithHttpPayloadLogging()))
+			var received []byte
+			r.POST("/users", func(c *gin.Context) {
+				received, _ = io.ReadAll(c.Request.Body)
+				c.Status(http.StatusCreated)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !bytes.Equal(received, []byte(tt.body)) {
+				t.Errorf("handler read %d bytes, want the whole %d bytes body", len(received), len(tt.body))
+			}
+			entries := logs.FilterField(zap.String("request_payload", tt.wantPayload)).All()
+			if len(entries) != 1 {
+				t.Errorf("no request log with request_payload %q", tt.wantPayload)
+			}
+		})
+	}
+}
@@ -1,11 +1,29 @@
 package interceptors
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
+	uranuserrors "github.com/tqhuy-dev/xgen-uranus/errors"
+)
+
+// Reasons of the errors returned for requests that cannot be bound.
+const (
+	InvalidBodyReason  = "INVALID_BODY"
+	InvalidQueryReason = "INVALID_QUERY"
+	InvalidUriReason   = "INVALID_URI"
 )
 
+// abortValidationFailed aborts the request with 400 and the errors.HttpBody listing the field violations of err.
+func abortValidationFailed(c *gin.Context, err error) {
+	uranuserrors.HttpAbort(c, validationError(err))
+}
+
+// abortBindFailed aborts the request with 400 and the errors.HttpBody of a binding error.
+func abortBindFailed(c *gin.Context, reason string, message string, err error) {
+	uranuserrors.HttpAbort(c, uranuserrors.BadRequest(reason, message).
+		WithMetadata(map[string]string{"details": err.Error()}).
+		WithCause(err))
+}
+
 // HttpValidateRequest is a helper function to validate request body in handlers
 // Usage in handler:
 //
@@ -16,22 +34,14 @@ import (
 func HttpValidateRequest(c *gin.Context, req interface{}) error {
 	// Bind JSON body to struct
 	if err := c.ShouldBindJSON(req); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		abortBindFailed(c, InvalidBodyReason, "Invalid request body", err)
 		return err
 	}
 
 	// Validate using custom validator if implemented
-	if validator, ok := req.(IValidator); ok {
-		if err := validator.Validate(); err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-				"error":   "Validation failed",
-				"details": err.Error(),
-			})
-			return err
-		}
+	if err := validate(req); err != nil {
+		abortValidationFailed(c, err)
+		return err
 	}
 
 	return nil
@@ -47,22 +57,14 @@ func HttpValidateRequest(c *gin.Context, req interface{}) error {
 func HttpValidateQuery(c *gin.Context, req interface{}) error {
 	// Bind query parameters to struct
 	if err := c.ShouldBindQuery(req); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid query parameters",
-			"details": err.Error(),
-		})
+		abortBindFailed(c, InvalidQueryReason, "Invalid query parameters", err)
 		return err
 	}
 
 	// Validate using custom validator if implemented
-	if validator, ok := req.(IValidator); ok {
-		if err := validator.Validate(); err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-				"error":   "Validation failed",
-				"details": err.Error(),
-			})
-			return err
-		}
+	if err := validate(req); err != nil {
+		abortValidationFailed(c, err)
+		return err
 	}
 
 	return nil
@@ -78,22 +80,14 @@ func HttpValidateQuery(c *gin.Context, req interface{}) error {
 func HttpValidateUri(c *gin.Context, req interface{}) error {
 	// Bind URI parameters to struct
 	if err := c.ShouldBindUri(req); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid URI parameters",
-			"details": err.Error(),
-		})
+		abortBindFailed(c, InvalidUriReason, "Invalid URI parameters", err)
 		return err
 	}
 
 	// Validate using custom validator if implemented
-	if validator, ok := req.(IValidator); ok {
-		if err := validator.Validate(); err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-				"error":   "Validation failed",
-				"details": err.Error(),
-			})
-			return err
-		}
+	if err := validate(req); err != nil {
+		abortValidationFailed(c, err)
+		return err
 	}
 
 	return nil
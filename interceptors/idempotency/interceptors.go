@@ -0,0 +1,282 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func hash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// callerScope identifies the caller owning an idempotency key, so that callers sending the same key do not
+// get each other's responses: the tenant and subject of authenticated callers, the client IP of anonymous ones.
+// Place the idempotency interceptors after the authentication and tenant interceptors.
+func callerScope(ctx context.Context, clientIP string) string {
+	meta, _ := reqctx.FromContext(ctx)
+	if meta.Subject != "" {
+		return meta.Tenant + "|sub:" + meta.Subject
+	}
+	if meta.ClientIP != "" {
+		clientIP = meta.ClientIP
+	}
+	return meta.Tenant + "|ip:" + clientIP
+}
+
+// peerIP returns the host of the peer address of a gRPC call.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// Idempotency returns a new unary server interceptor honoring the "idempotency-key" metadata
+// of the methods given to WithMethods.
+// The first successful response of a key is stored and replayed for duplicates, a duplicate
+// sent while the first request is running fails with codes.Aborted and a key reused with a
+// different request fails with codes.InvalidArgument. Failed requests release their key.
+func Idempotency(opts ...Option) grpc.UnaryServerInterceptor {
+	o := evaluateOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !o.applies(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		idempotencyKey := ""
+		if values := metadata.ValueFromIncomingContext(ctx, Header); len(values) > 0 {
+			idempotencyKey = values[0]
+		}
+		reqMsg, ok := req.(proto.Message)
+		if idempotencyKey == "" || !ok {
+			if o.requireKey {
+				return nil, status.Error(codes.InvalidArgument, "missing idempotency key")
+			}
+			return handler(ctx, req)
+		}
+
+		encodedReq, err := proto.MarshalOptions{Deterministic: true}.Marshal(reqMsg)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to encode request")
+		}
+		requestHash := hash(encodedReq)
+		storeKey := info.FullMethod + "|" + callerScope(ctx, peerIP(ctx)) + "|" + idempotencyKey
+
+		record, reserved, err := o.store.Reserve(ctx, storeKey, requestHash, o.ttl)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, "idempotency store unavailable")
+		}
+		if !reserved {
+			return replay(ctx, record, requestHash)
+		}
+
+		// the key is released unless the response is stored, including when the handler panics
+		completed := false
+		defer func() {
+			if !completed {
+				_ = o.store.Release(context.WithoutCancel(ctx), storeKey)
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		respMsg, ok := resp.(proto.Message)
+		if !ok {
+			return resp, nil
+		}
+		encodedResp, err := anypb.New(respMsg)
+		if err != nil {
+			return resp, nil
+		}
+		response, err := proto.Marshal(encodedResp)
+		if err != nil {
+			return resp, nil
+		}
+		err = o.store.Complete(context.WithoutCancel(ctx), storeKey, Record{RequestHash: requestHash, Response: response}, o.ttl)
+		completed = err == nil
+		return resp, nil
+	}
+}
+
+func replay(ctx context.Context, record *Record, requestHash string) (interface{}, error) {
+	if record.RequestHash != requestHash {
+		return nil, status.Error(codes.InvalidArgument, "idempotency key reused with a different request")
+	}
+	if !record.Completed {
+		return nil, status.Error(codes.Aborted, "a request with the same idempotency key is in progress")
+	}
+	encodedResp := &anypb.Any{}
+	if err := proto.Unmarshal(record.Response, encodedResp); err != nil {
+		return nil, status.Error(codes.Internal, "failed to decode stored response")
+	}
+	resp, err := encodedResp.UnmarshalNew()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to decode stored response")
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(ReplayedHeader, "true"))
+	ctxzap.AddFields(ctx, zap.Bool("idempotent_replayed", true))
+	return resp, nil
+}
+
+// bodyWriter copies the response body while it is written.
+type bodyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// HttpIdempotency is a Gin middleware honoring the Idempotency-Key header, on POST, PUT, PATCH and DELETE
+// routes by default.
+// The first 2xx response of a key is stored and replayed for duplicates with the Idempotent-Replayed
+// header, a duplicate sent while the first request is running gets 409 Conflict and a key reused
+// with a different request gets 422 Unprocessable Entity. Failed requests release their key.
+func HttpIdempotency(opts ...Option) gin.HandlerFunc {
+	o := evaluateOptions(opts)
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		if !o.httpApplies(c.Request.Method, c.FullPath()) {
+			c.Next()
+			return
+		}
+		idempotencyKey := c.GetHeader(Header)
+		if idempotencyKey == "" {
+			if o.requireKey {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error": "Missing idempotency key",
+				})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, o.maxBodyBytes))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "Request body too large",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := hash([]byte(c.Request.URL.RequestURI()), body)
+		storeKey := route + "|" + callerScope(c.Request.Context(), c.ClientIP()) + "|" + idempotencyKey
+
+		ctx := c.Request.Context()
+		record, reserved, err := o.store.Reserve(ctx, storeKey, requestHash, o.ttl)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Idempotency store unavailable",
+			})
+			return
+		}
+		if !reserved {
+			httpReplay(c, record, requestHash)
+			return
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				_ = o.store.Release(context.WithoutCancel(ctx), storeKey)
+			}
+		}()
+
+		// only the headers set by the handler are replayed, not the ones of this request (e.g. correlation ID)
+		headerBefore := c.Writer.Header().Clone()
+		writer := &bodyWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		c.Next()
+
+		statusCode := c.Writer.Status()
+		if statusCode < 200 || statusCode >= 300 {
+			return
+		}
+		header := make(map[string][]string)
+		for k, v := range c.Writer.Header() {
+			if _, ok := headerBefore[k]; !ok {
+				header[k] = v
+			}
+		}
+		err = o.store.Complete(context.WithoutCancel(ctx), storeKey, Record{
+			RequestHash: requestHash,
+			Response:    writer.body.Bytes(),
+			StatusCode:  statusCode,
+			Header:      header,
+		}, o.ttl)
+		completed = err == nil
+	}
+}
+
+func httpReplay(c *gin.Context, record *Record, requestHash string) {
+	if record.RequestHash != requestHash {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Idempotency key reused with a different request",
+		})
+		return
+	}
+	if !record.Completed {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": "A request with the same idempotency key is in progress",
+		})
+		return
+	}
+	for k, values := range record.Header {
+		for _, v := range values {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	c.Header(ReplayedHeader, "true")
+	interceptors.AddHttpLogFields(c, zap.Bool("idempotent_replayed", true))
+	c.Status(record.StatusCode)
+	_, _ = c.Writer.Write(record.Response)
+	c.Abort()
+}
@@ -0,0 +1,324 @@
+package idempotency
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const testMethod = "/payment.PaymentService/Charge"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func grpcContext(key string, subject string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51234}})
+	if subject != "" {
+		ctx = reqctx.WithMeta(ctx, reqctx.RequestMeta{Subject: subject})
+	}
+	if key != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(Header, key))
+	}
+	return ctx
+}
+
+// countingHandler answers with the number of calls it handled.
+func countingHandler(calls *atomic.Int64) grpc.UnaryHandler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.Int64(calls.Add(1)), nil
+	}
+}
+
+func callGrpc(t *testing.T, interceptor grpc.UnaryServerInterceptor, ctx context.Context, req proto.Message, handler grpc.UnaryHandler) (int64, error) {
+	t.Helper()
+	resp, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+	if err != nil {
+		return 0, err
+	}
+	return resp.(*wrapperspb.Int64Value).GetValue(), nil
+}
+
+func TestIdempotencyGrpc(t *testing.T) {
+	tests := []struct {
+		name      string
+		first     proto.Message
+		second    proto.Message
+		secondCtx context.Context
+		wantCode  codes.Code
+		wantValue int64
+	}{
+		{
+			name:      "duplicate is replayed",
+			first:     wrapperspb.String("charge 10"),
+			second:    wrapperspb.String("charge 10"),
+			secondCtx: grpcContext("k1", "alice"),
+			wantValue: 1,
+		},
+		{
+			name:      "key reused with a different request",
+			first:     wrapperspb.String("charge 10"),
+			second:    wrapperspb.String("charge 20"),
+			secondCtx: grpcContext("k1", "alice"),
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "same key of another caller",
+			first:     wrapperspb.String("charge 10"),
+			second:    wrapperspb.String("charge 10"),
+			secondCtx: grpcContext("k1", "bob"),
+			wantValue: 2,
+		},
+		{
+			name:      "another key",
+			first:     wrapperspb.String("charge 10"),
+			second:    wrapperspb.String("charge 10"),
+			secondCtx: grpcContext("k2", "alice"),
+			wantValue: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := &atomic.Int64{}
+			interceptor := Idempotency(WithMethods(testMethod))
+			if _, err := callGrpc(t, interceptor, grpcContext("k1", "alice"), tt.first, countingHandler(calls)); err != nil {
+				t.Fatal(err)
+			}
+			value, err := callGrpc(t, interceptor, tt.secondCtx, tt.second, countingHandler(calls))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("code = %s, want %s (%v)", status.Code(err), tt.wantCode, err)
+			}
+			if err == nil && value != tt.wantValue {
+				t.Errorf("response = %d, want %d", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestIdempotencyGrpcInFlight(t *testing.T) {
+	interceptor := Idempotency(WithMethods(testMethod))
+	req := wrapperspb.String("charge 10")
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := callGrpc(t, interceptor, grpcContext("k1", "alice"), req, func(ctx context.Context, req interface{}) (interface{}, error) {
+			close(started)
+			<-release
+			return wrapperspb.Int64(1), nil
+		})
+		done <- err
+	}()
+	<-started
+	_, err := callGrpc(t, interceptor, grpcContext("k1", "alice"), req, countingHandler(&atomic.Int64{}))
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("code = %s, want Aborted", status.Code(err))
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIdempotencyGrpcFailureReleasesKey(t *testing.T) {
+	interceptor := Idempotency(WithMethods(testMethod))
+	req := wrapperspb.String("charge 10")
+	_, err := callGrpc(t, interceptor, grpcContext("k1", "alice"), req, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unavailable, "down")
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("code = %s, want Unavailable", status.Code(err))
+	}
+	calls := &atomic.Int64{}
+	if value, err := callGrpc(t, interceptor, grpcContext("k1", "alice"), req, countingHandler(calls)); err != nil || value != 1 {
+		t.Errorf("retry = %d, %v, want the handler to run", value, err)
+	}
+}
+
+func TestIdempotencyGrpcMethods(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantRun int64
+	}{
+		{name: "not applied without methods", wantRun: 2},
+		{name: "applied to listed method", opts: []Option{WithMethods(testMethod)}, wantRun: 1},
+		{name: "not applied to other methods", opts: []Option{WithMethods("/payment.PaymentService/Get")}, wantRun: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := &atomic.Int64{}
+			interceptor := Idempotency(tt.opts...)
+			for i := 0; i < 2; i++ {
+				if _, err := callGrpc(t, interceptor, grpcContext("k1", "alice"), wrapperspb.String("charge 10"), countingHandler(calls)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if calls.Load() != tt.wantRun {
+				t.Errorf("handler ran %d times, want %d", calls.Load(), tt.wantRun)
+			}
+		})
+	}
+}
+
+func newRouter(calls *atomic.Int64, opts ...Option) *gin.Engine {
+	r := gin.New()
+	r.Use(HttpIdempotency(opts...))
+	handler := func(c *gin.Context) {
+		c.Header("X-Charge", "created")
+		c.JSON(http.StatusCreated, gin.H{"call": calls.Add(1)})
+	}
+	r.POST("/payments", handler)
+	r.GET("/payments", handler)
+	return r
+}
+
+func doHttp(r *gin.Engine, method string, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/payments", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHttpIdempotency(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		secondKey    string
+		secondBody   string
+		wantStatus   int
+		wantBody     string
+		wantReplayed bool
+	}{
+		{
+			name:         "duplicate is replayed",
+			method:       http.MethodPost,
+			secondKey:    "k1",
+			secondBody:   `{"amount":10}`,
+			wantStatus:   http.StatusCreated,
+			wantBody:     `{"call":1}`,
+			wantReplayed: true,
+		},
+		{
+			name:       "key reused with a different body",
+			method:     http.MethodPost,
+			secondKey:  "k1",
+			secondBody: `{"amount":20}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "another key",
+			method:     http.MethodPost,
+			secondKey:  "k2",
+			secondBody: `{"amount":10}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"call":2}`,
+		},
+		{
+			name:       "read-only route not applied",
+			method:     http.MethodGet,
+			secondKey:  "k1",
+			secondBody: `{"amount":10}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"call":2}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRouter(&atomic.Int64{})
+			if w := doHttp(r, tt.method, "k1", `{"amount":10}`); w.Code != http.StatusCreated {
+				t.Fatalf("first status = %d", w.Code)
+			}
+			w := doHttp(r, tt.method, tt.secondKey, tt.secondBody)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+			if replayed := w.Header().Get(ReplayedHeader) == "true"; replayed != tt.wantReplayed {
+				t.Errorf("replayed = %t, want %t", replayed, tt.wantReplayed)
+			}
+			if tt.wantReplayed && w.Header().Get("X-Charge") != "created" {
+				t.Error("handler header not replayed")
+			}
+		})
+	}
+}
+
+func TestHttpIdempotencyInFlight(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	r := gin.New()
+	r.Use(HttpIdempotency())
+	r.POST("/payments", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusCreated)
+	})
+	done := make(chan int)
+	go func() {
+		done <- doHttp(r, http.MethodPost, "k1", `{}`).Code
+	}()
+	<-started
+	if w := doHttp(r, http.MethodPost, "k1", `{}`); w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	close(release)
+	if code := <-done; code != http.StatusCreated {
+		t.Errorf("first status = %d", code)
+	}
+}
+
+func TestHttpIdempotencyBodyTooLarge(t *testing.T) {
+	calls := &atomic.Int64{}
+	r := newRouter(calls, WithMaxBodyBytes(8))
+	if w := doHttp(r, http.MethodPost, "k1", `{"amount":10}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+	if calls.Load() != 0 {
+		t.Error("handler ran for a body over the limit")
+	}
+}
+
+func TestHttpIdempotencyCallerScope(t *testing.T) {
+	calls := &atomic.Int64{}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(reqctx.WithMeta(c.Request.Context(), reqctx.RequestMeta{Subject: c.GetHeader("X-Subject")}))
+	}, HttpIdempotency())
+	r.POST("/payments", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"call": calls.Add(1), "subject": reqctx.Subject(c)})
+	})
+	send := func(subject string) string {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{}`))
+		req.Header.Set(Header, "shared")
+		req.Header.Set("X-Subject", subject)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	alice := send("alice")
+	if bob := send("bob"); bob == alice {
+		t.Errorf("bob got the response of alice: %s", bob)
+	}
+	if again := send("alice"); again != alice {
+		t.Errorf("alice replay = %s, want %s", again, alice)
+	}
+}
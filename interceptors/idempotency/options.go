@@ -0,0 +1,105 @@
+package idempotency
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// Header carries the idempotency key, in gRPC metadata and HTTP headers.
+	Header = "idempotency-key"
+	// ReplayedHeader is set on HTTP responses replayed from the store.
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+var (
+	defaultOptions = &options{
+		ttl:          24 * time.Hour,
+		maxBodyBytes: 1 << 20,
+	}
+)
+
+type options struct {
+	store        Store
+	ttl          time.Duration
+	methods      map[string]struct{}
+	requireKey   bool
+	maxBodyBytes int64
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	for _, o := range opts {
+		o(optCopy)
+	}
+	if optCopy.store == nil {
+		optCopy.store = NewMemoryStore()
+	}
+	return optCopy
+}
+
+// applies reports whether idempotency keys are honored for a gRPC method, only the ones given to WithMethods.
+func (o *options) applies(method string) bool {
+	_, ok := o.methods[method]
+	return ok
+}
+
+// httpApplies reports whether idempotency keys are honored for the gin route of a request with method:
+// the routes given to WithMethods, or every POST, PUT, PATCH and DELETE route when none is given.
+func (o *options) httpApplies(method string, route string) bool {
+	if len(o.methods) > 0 {
+		_, ok := o.methods[method+" "+route]
+		return ok
+	}
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return route != ""
+	}
+	return false
+}
+
+type Option func(*options)
+
+// WithStore keeps the records in store instead of in memory.
+func WithStore(store Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// WithTTL sets how long responses are replayed, 24 hours by default.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithMethods honors idempotency keys on the given gRPC methods or gin routes ("POST /payments").
+// By default no gRPC method honors them, as read-only RPCs cannot be told apart, and every
+// POST, PUT, PATCH and DELETE gin route does.
+func WithMethods(methods ...string) Option {
+	return func(o *options) {
+		if o.methods == nil {
+			o.methods = make(map[string]struct{}, len(methods))
+		}
+		for _, method := range methods {
+			o.methods[method] = struct{}{}
+		}
+	}
+}
+
+// WithRequireKey rejects the requests to the methods that have no idempotency key.
+func WithRequireKey() Option {
+	return func(o *options) {
+		o.requireKey = true
+	}
+}
+
+// WithMaxBodyBytes caps the size of the HTTP request bodies read to fingerprint requests, 1MB by default.
+// Larger bodies are rejected with 413 Request Entity Too Large.
+func WithMaxBodyBytes(maxBodyBytes int64) Option {
+	return func(o *options) {
+		o.maxBodyBytes = maxBodyBytes
+	}
+}
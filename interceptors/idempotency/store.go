@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Record is what is kept for an idempotency key.
+type Record struct {
+	// RequestHash identifies the payload first sent with the key.
+	RequestHash string
+	// Completed is false while the first request is being handled.
+	Completed bool
+	// Response is the encoded response, an anypb.Any for gRPC and the body for HTTP.
+	Response []byte
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Header holds the HTTP response headers.
+	Header map[string][]string
+}
+
+// Store keeps the records of idempotency keys. Implement it on a shared backend
+// (e.g. Redis with SET NX) so that duplicates are detected across replicas.
+type Store interface {
+	// Reserve records an in-progress request for key when it is unknown and returns true.
+	// Otherwise it returns the existing record and false.
+	Reserve(ctx context.Context, key string, requestHash string, ttl time.Duration) (*Record, bool, error)
+	// Complete stores the response of a reserved key.
+	Complete(ctx context.Context, key string, record Record, ttl time.Duration) error
+	// Release forgets a reserved key, so that the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+type entry struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the records of a single process until their TTL expires.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+// sweepInterval is how often expired records are dropped.
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*entry), lastSweep: time.Now()}
+}
+
+func (s *MemoryStore) Reserve(_ context.Context, key string, requestHash string, ttl time.Duration) (*Record, bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	if e, ok := s.entries[key]; ok && now.Before(e.expiresAt) {
+		record := e.record
+		return &record, false, nil
+	}
+	s.entries[key] = &entry{
+		record:    Record{RequestHash: requestHash},
+		expiresAt: now.Add(ttl),
+	}
+	return nil, true, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, key string, record Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record.Completed = true
+	s.entries[key] = &entry{record: record, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -6,9 +6,10 @@ import (
 	"time"
 
 	grpc_logging "github.com/grpc-ecosystem/go-grpc-middleware/logging"
-	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen-uranus/logging"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
@@ -65,6 +66,9 @@ type options struct {
 	messageFunc     MessageProducer
 	timestampFormat string
 	appName         string
+	payload         payloadLogging
+	sampler         *requestSampler
+	slowThreshold   time.Duration
 }
 
 type Option func(*options)
@@ -77,16 +81,142 @@ func WithAppName(appName string) Option {
 	}
 }
 
+// WithPayloadLogging logs the request and response of the given full methods ("/pkg.Service/Method"),
+// of every method when none is given. Sensitive fields are redacted, see WithRedactedFields.
+func WithPayloadLogging(methods ...string) Option {
+	return func(o *options) {
+		o.payload.enable(methods)
+	}
+}
+
+// WithPayloadMaxBytes caps the size of logged payloads, 4KB by default.
+func WithPayloadMaxBytes(maxBytes int) Option {
+	return func(o *options) {
+		o.payload.maxBytes = maxBytes
+	}
+}
+
+// WithRedactedFields masks more fields in logged payloads, in addition to DefaultRedactedFields and
+// the proto fields declared with the debug_redact option.
+func WithRedactedFields(names ...string) Option {
+	return func(o *options) {
+		o.payload.redact(names...)
+	}
+}
+
+// WithSampling samples the logs of successful calls per method with config, see zap.SamplingConfig.
+// Failed calls and calls slower than the threshold of WithSlowThreshold are always logged.
+func WithSampling(config zap.SamplingConfig) Option {
+	return func(o *options) {
+		o.sampler = newRequestSampler(config)
+	}
+}
+
+// WithSlowThreshold always logs the calls lasting longer than threshold with the field slow, even when
+// they are sampled out.
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(o *options) {
+		o.slowThreshold = threshold
+	}
+}
+
+// WithSkipMethods does not log the successful calls to the health check and reflection services and
+// to the given full methods or services, see SkipMethodsDecider.
+func WithSkipMethods(methods ...string) Option {
+	return func(o *options) {
+		o.shouldLog = SkipMethodsDecider(methods...)
+	}
+}
+
 type CodeToLevel func(code codes.Code) zapcore.Level
 
+// WithLevels customizes the function for mapping gRPC return codes and interceptor log level statements,
+// DefaultCodeToLevel by default.
+func WithLevels(f CodeToLevel) Option {
+	return func(o *options) {
+		o.levelFunc = f
+	}
+}
+
+// WithDecider customizes the function for deciding if the gRPC interceptor logs should log.
+// See SkipMethodsDecider to skip health checks and reflection.
+func WithDecider(f grpc_logging.Decider) Option {
+	return func(o *options) {
+		o.shouldLog = f
+	}
+}
+
+// WithCodes customizes the function for mapping errors to error codes.
+func WithCodes(f grpc_logging.ErrorToCode) Option {
+	return func(o *options) {
+		o.codeFunc = f
+	}
+}
+
+// WithDurationField customizes the function for mapping request durations to Zap fields,
+// DurationToTimeMillisField by default.
+func WithDurationField(f DurationToField) Option {
+	return func(o *options) {
+		o.durationFunc = f
+	}
+}
+
+// WithMessageProducer customizes the function for message formation, DefaultMessageProducer by default.
+func WithMessageProducer(f MessageProducer) Option {
+	return func(o *options) {
+		o.messageFunc = f
+	}
+}
+
+// WithTimestampFormat customizes the format of the start_time field, time.RFC3339 by default.
+func WithTimestampFormat(format string) Option {
+	return func(o *options) {
+		o.timestampFormat = format
+	}
+}
+
+// DefaultSkippedMethods are the health check and reflection methods, skipped by SkipMethodsDecider.
+var DefaultSkippedMethods = []string{
+	"/grpc.health.v1.Health/Check",
+	"/grpc.health.v1.Health/Watch",
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// SkipMethodsDecider returns a decider that does not log successful calls to DefaultSkippedMethods
+// and to the given full methods. A service ("/pkg.Service/") skips all of its methods.
+// Failed calls are always logged.
+func SkipMethodsDecider(methods ...string) grpc_logging.Decider {
+	skipped := make(map[string]struct{}, len(DefaultSkippedMethods)+len(methods))
+	for _, method := range append(append([]string{}, DefaultSkippedMethods...), methods...) {
+		skipped[method] = struct{}{}
+	}
+	return func(fullMethodName string, err error) bool {
+		if err != nil {
+			return true
+		}
+		if _, ok := skipped[fullMethodName]; ok {
+			return false
+		}
+		service, _ := path.Split(fullMethodName)
+		_, ok := skipped[service]
+		return !ok
+	}
+}
+
 // DefaultDurationToField is the default implementation of converting request duration to a Zap field.
 var DefaultDurationToField = DurationToTimeMillisField
 
-// DurationToTimeMillisField converts the duration to milliseconds and uses the key `grpc.time_ms`.
+// DurationToTimeMillisField converts the duration to milliseconds and uses the key `duration_ms`.
 func DurationToTimeMillisField(duration time.Duration) zapcore.Field {
 	return zap.Float32("duration_ms", durationToMilliseconds(duration))
 }
 
+// DurationToDurationField uses a Duration field with the key `duration`.
+func DurationToDurationField(duration time.Duration) zapcore.Field {
+	return zap.Duration("duration", duration)
+}
+
 func durationToMilliseconds(duration time.Duration) float32 {
 	return float32(duration.Nanoseconds()/1000) / 1000
 }
@@ -105,23 +235,29 @@ var (
 // DefaultMessageProducer writes the default message
 func DefaultMessageProducer(ctx context.Context, msg string, level zapcore.Level, code codes.Code, err error, duration zapcore.Field, appName string) {
 	// re-extract logger from newCtx, as it may have extra fields that changed in the holder.
-	correlationId, _ := ctx.Value(common.CorrelationIdKey).(string)
+	correlationId := reqctx.CorrelationId(ctx)
 	fields := []zapcore.Field{
 		zap.String("code", code.String()),
 		zap.String("app_name", appName),
 		zap.String("correlation_id", correlationId),
 		duration,
 	}
+	fields = append(fields, TraceFields(ctx)...)
 	if err != nil {
 		fields = append(fields, zap.Error(err))
 	}
 	ctxzap.Extract(ctx).Check(level, msg).Write(fields...)
 }
 
+// TraceFields returns the trace_id and span_id fields of ctx, none when ctx is not traced.
+func TraceFields(ctx context.Context) []zapcore.Field {
+	return logging.TraceFields(ctx)
+}
+
 func evaluateServerOpt(opts []Option) *options {
 	optCopy := &options{}
 	*optCopy = *defaultOptions
-	optCopy.levelFunc = grpc_zap.DefaultCodeToLevel
+	optCopy.payload = newPayloadLogging()
 	for _, o := range opts {
 		o(optCopy)
 	}
@@ -133,7 +269,7 @@ func ZapLogInterceptor(logger *zap.Logger, opts ...Option) grpc.UnaryServerInter
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		startTime := time.Now()
 
-		newCtx := newLoggerForCall(ctx, logger, info.FullMethod, startTime, o.timestampFormat)
+		newCtx := newLoggerForCall(ctx, logger, info.FullMethod, startTime, o.timestampFormat, o.appName)
 
 		resp, err := handler(newCtx, req)
 		if !o.shouldLog(info.FullMethod, err) {
@@ -141,20 +277,35 @@ func ZapLogInterceptor(logger *zap.Logger, opts ...Option) grpc.UnaryServerInter
 		}
 		code := o.codeFunc(err)
 		level := o.levelFunc(code)
-		duration := o.durationFunc(time.Since(startTime))
+		elapsed := time.Since(startTime)
+		msg := "finished unary call with code " + code.String()
+		slow := o.slowThreshold > 0 && elapsed > o.slowThreshold
+		if slow {
+			ctxzap.AddFields(newCtx, zap.Bool("slow", true))
+		} else if err == nil && o.sampler != nil && !o.sampler.sample(info.FullMethod, msg, level) {
+			return resp, err
+		}
+		duration := o.durationFunc(elapsed)
+		if o.payload.applies(info.FullMethod) {
+			ctxzap.AddFields(newCtx,
+				zap.String("request_payload", o.payload.render(req)),
+				zap.String("response_payload", o.payload.render(resp)))
+		}
 
-		o.messageFunc(newCtx, "finished unary call with code "+code.String(), level, code, err, duration, o.appName)
+		o.messageFunc(newCtx, msg, level, code, err, duration, o.appName)
 		return resp, err
 	}
 }
 
-func newLoggerForCall(ctx context.Context, logger *zap.Logger, fullMethodString string, start time.Time, timestampFormat string) context.Context {
+func newLoggerForCall(ctx context.Context, logger *zap.Logger, fullMethodString string, start time.Time, timestampFormat string, appName string) context.Context {
 	var f []zapcore.Field
 	f = append(f, zap.String("start_time", start.Format(timestampFormat)))
 	//if d, ok := ctx.Deadline(); ok {
 	//	f = append(f, zap.String("grpc.request.deadline", d.Format(timestampFormat)))
 	//}
 	callLog := logger.With(append(f, serverCallFields(fullMethodString)...)...)
+	// handlers get the call logger with logging.FromContext, the final log line adds the app name itself
+	ctx = logging.ToContext(ctx, callLog.With(zap.String(common.LogKeyAppName, appName)))
 	return ctxzap.ToContext(ctx, callLog)
 }
 
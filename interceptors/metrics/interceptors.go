@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+func (m *Metrics) observeGrpc(ctx context.Context, method string, start time.Time, err error) {
+	labels := m.labelValues(ctx, method, status.Code(err).String())
+	m.grpcHandled.WithLabelValues(labels...).Inc()
+	m.grpcDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+}
+
+// Measure returns a new unary server interceptor recording the count, latency and in-flight RPCs of every method.
+func (m *Metrics) Measure() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		inFlight := m.grpcInFlight.WithLabelValues(info.FullMethod)
+		inFlight.Inc()
+		start := time.Now()
+		defer func() {
+			inFlight.Dec()
+			m.observeGrpc(ctx, info.FullMethod, start, err)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new streaming server interceptor recording the count, latency
+// and in-flight streams of every method.
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		inFlight := m.grpcInFlight.WithLabelValues(info.FullMethod)
+		inFlight.Inc()
+		start := time.Now()
+		defer func() {
+			inFlight.Dec()
+			m.observeGrpc(stream.Context(), info.FullMethod, start, err)
+		}()
+		return handler(srv, stream)
+	}
+}
+
+// HttpMeasure is a Gin middleware recording the count, latency and in-flight requests of every route.
+// Requests are labelled by gin's route template (c.FullPath()), unmatched requests by "unmatched"
+// with any method, so that clients cannot grow the label sets.
+func (m *Metrics) HttpMeasure() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		method := c.Request.Method
+		if route == "" {
+			route = "unmatched"
+			method = "any"
+		}
+		inFlight := m.httpInFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		start := time.Now()
+		defer func() {
+			inFlight.Dec()
+			// the tenant middleware runs after this one, the request context is read once it returns
+			labels := m.labelValues(c.Request.Context(), method, route, strconv.Itoa(c.Writer.Status()))
+			m.httpRequests.WithLabelValues(labels...).Inc()
+			m.httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		}()
+		c.Next()
+	}
+}
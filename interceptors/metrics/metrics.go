@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+)
+
+// Path is where the metrics are exposed.
+const Path = "/metrics"
+
+// Metrics holds the request counters, latency histograms and in-flight gauges of the gRPC and HTTP servers.
+// gRPC metrics are labelled by full method and status code, HTTP metrics by method, gin route template
+// and status so that label cardinality stays bounded, and optionally by tenant, see WithTenantLabel.
+type Metrics struct {
+	gatherer    prometheus.Gatherer
+	tenantLabel bool
+
+	grpcHandled  *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	grpcInFlight *prometheus.GaugeVec
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight *prometheus.GaugeVec
+
+	panics prometheus.Counter
+}
+
+// New creates the metrics and registers them, on the default Prometheus registry unless WithRegistry is given.
+// It panics when they are already registered, create them once and share them between servers.
+func New(opts ...Option) *Metrics {
+	o := evaluateOptions(opts)
+	m := &Metrics{
+		gatherer:    o.gatherer,
+		tenantLabel: o.tenantLabel,
+		grpcHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "grpc_server_handled_total",
+			Help:      "Total number of RPCs completed on the server, by method and code.",
+		}, o.labels("method", "code")),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "grpc_server_handling_seconds",
+			Help:      "Latency of RPCs handled by the server, by method and code.",
+			Buckets:   o.buckets,
+		}, o.labels("method", "code")),
+		grpcInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: o.namespace,
+			Name:      "grpc_server_in_flight",
+			Help:      "Number of RPCs being handled by the server, by method.",
+		}, []string{"method"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "http_server_requests_total",
+			Help:      "Total number of HTTP requests completed on the server, by method, route and status.",
+		}, o.labels("method", "route", "status")),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Name:      "http_server_request_duration_seconds",
+			Help:      "Latency of HTTP requests handled by the server, by method, route and status.",
+			Buckets:   o.buckets,
+		}, o.labels("method", "route", "status")),
+		httpInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: o.namespace,
+			Name:      "http_server_in_flight",
+			Help:      "Number of HTTP requests being handled by the server, by method and route.",
+		}, []string{"method", "route"}),
+		panics: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Name:      "panics_recovered_total",
+			Help:      "Total number of panics recovered in gRPC and HTTP handlers.",
+		}),
+	}
+	o.registerer.MustRegister(m.grpcHandled, m.grpcDuration, m.grpcInFlight,
+		m.httpRequests, m.httpDuration, m.httpInFlight, m.panics)
+	return m
+}
+
+// labels returns the labels of the request counters and latency histograms.
+func (o *options) labels(names ...string) []string {
+	if o.tenantLabel {
+		return append(names, "tenant")
+	}
+	return names
+}
+
+// labelValues returns the label values of the request counters and latency histograms for the request of ctx.
+func (m *Metrics) labelValues(ctx context.Context, values ...string) []string {
+	if m.tenantLabel {
+		return append(values, reqctx.Tenant(ctx))
+	}
+	return values
+}
+
+// PanicCounter counts the recovered panics, hand it to recovery.WithPanicCounter.
+func (m *Metrics) PanicCounter() prometheus.Counter {
+	return m.panics
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{})
+}
+
+// AdminServer returns a server exposing the metrics on /metrics of port, to keep them off the public port.
+// Hand it to transport.UranusServer.WithAdminServer or start it yourself.
+func (m *Metrics) AdminServer(port int) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(Path, m.Handler())
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	defaultOptions = &options{
+		registerer: prometheus.DefaultRegisterer,
+		gatherer:   prometheus.DefaultGatherer,
+		buckets:    prometheus.DefBuckets,
+	}
+)
+
+type options struct {
+	registerer prometheus.Registerer
+	gatherer   prometheus.Gatherer
+	namespace  string
+	buckets    []float64
+	// tenantLabel labels the request counters and latency histograms by tenant
+	tenantLabel bool
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	for _, o := range opts {
+		o(optCopy)
+	}
+	return optCopy
+}
+
+type Option func(*options)
+
+// WithRegistry registers the metrics on registry and exposes it instead of the default Prometheus registry.
+func WithRegistry(registry *prometheus.Registry) Option {
+	return func(o *options) {
+		o.registerer = registry
+		o.gatherer = registry
+	}
+}
+
+// WithNamespace prefixes the metric names with namespace.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithBuckets sets the latency histogram buckets in seconds, prometheus.DefBuckets by default.
+func WithBuckets(buckets ...float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// WithTenantLabel adds the tenant of the request (see tenant.Tenant and tenant.HttpTenant) as a "tenant" label
+// of the request counters and latency histograms. Requests without a tenant have an empty label.
+// The gRPC tenant interceptors must run before Measure. Only use it with a bounded number of tenants.
+func WithTenantLabel() Option {
+	return func(o *options) {
+		o.tenantLabel = true
+	}
+}
@@ -0,0 +1,246 @@
+package interceptors
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	// RedactedValue replaces the values of sensitive fields in logged payloads.
+	RedactedValue = "[REDACTED]"
+	// defaultPayloadMaxBytes caps the size of logged payloads.
+	defaultPayloadMaxBytes = 4096
+	// redactTag marks sensitive struct fields: `json:"pin" redact:"true"`.
+	redactTag = "redact"
+)
+
+// DefaultRedactedFields are masked in every logged payload, compared case-insensitively without "_" and "-".
+var DefaultRedactedFields = []string{
+	"password", "passwd", "secret", "token", "accesstoken", "refreshtoken", "idtoken",
+	"apikey", "authorization", "cookie", "cardnumber", "creditcard", "cvv", "pin", "ssn",
+}
+
+func normalizeFieldName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "", "-", "").Replace(name)
+}
+
+// payloadLogging holds the payload logging options shared by ZapLogInterceptor and HttpZapLogMiddleware.
+type payloadLogging struct {
+	enabled  bool
+	methods  map[string]struct{}
+	maxBytes int
+	redacted map[string]struct{}
+}
+
+func newPayloadLogging() payloadLogging {
+	p := payloadLogging{maxBytes: defaultPayloadMaxBytes, redacted: make(map[string]struct{})}
+	p.redact(DefaultRedactedFields...)
+	return p
+}
+
+func (p *payloadLogging) enable(methods []string) {
+	p.enabled = true
+	if len(methods) == 0 {
+		return
+	}
+	if p.methods == nil {
+		p.methods = make(map[string]struct{}, len(methods))
+	}
+	for _, method := range methods {
+		p.methods[method] = struct{}{}
+	}
+}
+
+func (p *payloadLogging) redact(names ...string) {
+	redacted := make(map[string]struct{}, len(p.redacted)+len(names))
+	for name := range p.redacted {
+		redacted[name] = struct{}{}
+	}
+	for _, name := range names {
+		redacted[normalizeFieldName(name)] = struct{}{}
+	}
+	p.redacted = redacted
+}
+
+// applies reports whether the payloads of method are logged.
+func (p *payloadLogging) applies(method string) bool {
+	if !p.enabled {
+		return false
+	}
+	if len(p.methods) == 0 {
+		return true
+	}
+	_, ok := p.methods[method]
+	return ok
+}
+
+func (p *payloadLogging) isRedacted(name string) bool {
+	_, ok := p.redacted[normalizeFieldName(name)]
+	return ok
+}
+
+func (p *payloadLogging) truncate(payload string) string {
+	if len(payload) <= p.maxBytes {
+		return payload
+	}
+	return payload[:p.maxBytes] + "...(truncated)"
+}
+
+// render returns the redacted and truncated rendering of v. Protobuf messages are rendered with protojson,
+// other values with encoding/json.
+func (p *payloadLogging) render(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if msg, ok := v.(proto.Message); ok {
+		return p.truncate(p.renderProto(msg))
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return p.truncate(p.redactJson(b, structRedactedFields(reflect.TypeOf(v))))
+}
+
+// renderBody returns the redacted and truncated rendering of an HTTP body. JSON and form bodies are
+// redacted field by field, other bodies are not logged.
+func (p *payloadLogging) renderBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	switch {
+	case strings.Contains(contentType, "json"):
+		return p.truncate(p.redactJson(body, nil))
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return ""
+		}
+		for k := range values {
+			if p.isRedacted(k) {
+				values.Set(k, RedactedValue)
+			}
+		}
+		return p.truncate(values.Encode())
+	default:
+		return ""
+	}
+}
+
+func (p *payloadLogging) renderProto(msg proto.Message) string {
+	redacted := proto.Clone(msg)
+	p.redactProto(redacted.ProtoReflect())
+	b, err := protojson.Marshal(redacted)
+	if err != nil {
+		return ""
+	}
+	// keys of google.protobuf.Struct and map fields are not proto fields
+	return p.redactJson(b, nil)
+}
+
+// redactProto masks the fields marked with the debug_redact option or named like a sensitive field.
+// Sensitive string fields are replaced with RedactedValue, other sensitive fields are cleared.
+func (p *payloadLogging) redactProto(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if p.isSensitiveProtoField(fd) {
+			if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+				m.Set(fd, protoreflect.ValueOfString(RedactedValue))
+			} else {
+				m.Clear(fd)
+			}
+			return true
+		}
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				p.redactProto(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				p.redactProto(mv.Message())
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			p.redactProto(v.Message())
+		}
+		return true
+	})
+}
+
+func (p *payloadLogging) isSensitiveProtoField(fd protoreflect.FieldDescriptor) bool {
+	if options, ok := fd.Options().(*descriptorpb.FieldOptions); ok && options.GetDebugRedact() {
+		return true
+	}
+	return p.isRedacted(string(fd.Name())) || p.isRedacted(fd.JSONName())
+}
+
+// redactJson masks the sensitive keys of a JSON document, extra holds more sensitive keys.
+// Documents that are not JSON are not logged.
+func (p *payloadLogging) redactJson(b []byte, extra map[string]struct{}) string {
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return ""
+	}
+	doc = p.redactJsonValue(doc, extra)
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return ""
+	}
+	return string(redacted)
+}
+
+func (p *payloadLogging) redactJsonValue(v interface{}, extra map[string]struct{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			if _, ok := extra[k]; ok || p.isRedacted(k) {
+				value[k] = RedactedValue
+				continue
+			}
+			value[k] = p.redactJsonValue(child, extra)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = p.redactJsonValue(child, extra)
+		}
+	}
+	return v
+}
+
+// structRedactedFields returns the JSON names of the struct fields tagged `redact:"true"`, in t and its nested structs.
+func structRedactedFields(t reflect.Type) map[string]struct{} {
+	names := make(map[string]struct{})
+	collectRedactedFields(t, names, make(map[reflect.Type]bool))
+	return names
+}
+
+func collectRedactedFields(t reflect.Type, names map[string]struct{}, seen map[reflect.Type]bool) {
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get(redactTag) == "true" {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			names[name] = struct{}{}
+			continue
+		}
+		collectRedactedFields(field.Type, names, seen)
+	}
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// headers renders a result as the standard rate limit headers, durations in whole seconds.
+func headers(result Result) map[string]string {
+	h := map[string]string{
+		HeaderLimit:     strconv.Itoa(result.Limit),
+		HeaderRemaining: strconv.Itoa(result.Remaining),
+		HeaderReset:     strconv.Itoa(ceilSeconds(result.Reset)),
+	}
+	if !result.Allowed {
+		h[HeaderRetryAfter] = strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1))
+	}
+	return h
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func trailer(result Result) metadata.MD {
+	md := metadata.MD{}
+	for k, v := range headers(result) {
+		md.Set(k, v)
+	}
+	return md
+}
+
+type limiter struct {
+	*options
+}
+
+// take counts a request of the caller against the rate of method. It returns false
+// when the method is unlimited or the store failed, in which case the request is let through.
+func (l *limiter) take(ctx context.Context, method, caller string) (Result, bool, error) {
+	rate, limited := l.config.rateFor(method)
+	if !limited {
+		return Result{}, false, nil
+	}
+	result, err := l.store.Take(ctx, method+"|"+caller, rate)
+	if err != nil {
+		return Result{}, false, err
+	}
+	return result, true, nil
+}
+
+func errRateLimited(result Result) error {
+	return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %s", result.RetryAfter.Round(time.Millisecond))
+}
+
+// Limit returns a new unary server interceptor that rejects requests over their rate with
+// codes.ResourceExhausted. The rate limit state is sent in the trailers.
+func Limit(opts ...Option) grpc.UnaryServerInterceptor {
+	l := &limiter{evaluateOptions(opts)}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		result, limited, err := l.take(ctx, info.FullMethod, l.keyExtractor.Grpc(ctx))
+		if err != nil {
+			ctxzap.AddFields(ctx, zap.NamedError("rate_limit_error", err))
+		}
+		if !limited {
+			return handler(ctx, req)
+		}
+
+		_ = grpc.SetTrailer(ctx, trailer(result))
+		if !result.Allowed {
+			ctxzap.AddFields(ctx, zap.Bool("rate_limited", true))
+			return nil, errRateLimited(result)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new streaming server interceptor counting every stream as one request.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	l := &limiter{evaluateOptions(opts)}
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		result, limited, err := l.take(ctx, info.FullMethod, l.keyExtractor.Grpc(ctx))
+		if err != nil {
+			ctxzap.AddFields(ctx, zap.NamedError("rate_limit_error", err))
+		}
+		if !limited {
+			return handler(srv, stream)
+		}
+
+		stream.SetTrailer(trailer(result))
+		if !result.Allowed {
+			return errRateLimited(result)
+		}
+		return handler(srv, stream)
+	}
+}
+
+// HttpLimit is a Gin middleware that rejects requests over their rate with 429 Too Many Requests.
+// The rate limit state is sent in the RateLimit-* and Retry-After headers.
+func HttpLimit(opts ...Option) gin.HandlerFunc {
+	l := &limiter{evaluateOptions(opts)}
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		result, limited, err := l.take(c.Request.Context(), route, l.keyExtractor.Http(c))
+		if err != nil {
+			interceptors.AddHttpLogFields(c, zap.NamedError("rate_limit_error", err))
+		}
+		if !limited {
+			c.Next()
+			return
+		}
+
+		for k, v := range headers(result) {
+			c.Header(k, v)
+		}
+		if !result.Allowed {
+			interceptors.AddHttpLogFields(c, zap.Bool("rate_limited", true))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Rate limit exceeded",
+			})
+			return
+		}
+		c.Next()
+	}
+}
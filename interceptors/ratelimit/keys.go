@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// KeyExtractor identifies the caller a request is counted against, for each transport.
+type KeyExtractor struct {
+	Grpc func(ctx context.Context) string
+	Http func(c *gin.Context) string
+}
+
+// ByClientIP counts requests per client IP. For gRPC the peer address is the client unless it is one of
+// trustedProxies (IPs or CIDRs), in which case the "x-forwarded-for" entries are read from the right,
+// skipping the trusted proxies, so that callers cannot pick their bucket by sending the header.
+// For gin the engine trusted proxies apply. It panics when a trusted proxy cannot be parsed.
+func ByClientIP(trustedProxies ...string) KeyExtractor {
+	trusted := parseTrustedProxies(trustedProxies)
+	return KeyExtractor{
+		Grpc: func(ctx context.Context) string {
+			p, ok := peer.FromContext(ctx)
+			if !ok || p.Addr == nil {
+				return ""
+			}
+			host, _, err := net.SplitHostPort(p.Addr.String())
+			if err != nil {
+				host = p.Addr.String()
+			}
+			if !trusted.contains(host) {
+				return host
+			}
+			md, _ := metadata.FromIncomingContext(ctx)
+			forwarded := strings.Split(strings.Join(md.Get("x-forwarded-for"), ","), ",")
+			for i := len(forwarded) - 1; i >= 0; i-- {
+				ip := strings.TrimSpace(forwarded[i])
+				if ip == "" {
+					continue
+				}
+				if !trusted.contains(ip) {
+					return ip
+				}
+				host = ip
+			}
+			return host
+		},
+		Http: func(c *gin.Context) string {
+			return c.ClientIP()
+		},
+	}
+}
+
+// trustedProxies are the networks allowed to report the client IP in "x-forwarded-for".
+type trustedProxies []netip.Prefix
+
+func parseTrustedProxies(proxies []string) trustedProxies {
+	prefixes := make(trustedProxies, 0, len(proxies))
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				panic(fmt.Sprintf("ratelimit: invalid trusted proxy %q: %v", proxy, err))
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			panic(fmt.Sprintf("ratelimit: invalid trusted proxy %q: %v", proxy, err))
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes
+}
+
+func (t trustedProxies) contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ByMetadata counts requests per value of a gRPC metadata key or HTTP header.
+func ByMetadata(name string) KeyExtractor {
+	return KeyExtractor{
+		Grpc: func(ctx context.Context) string {
+			return firstMetadata(ctx, name)
+		},
+		Http: func(c *gin.Context) string {
+			return c.GetHeader(name)
+		},
+	}
+}
+
+// ByAPIKey counts requests per API key sent in the "x-api-key" metadata or header.
+func ByAPIKey() KeyExtractor {
+	return ByMetadata("x-api-key")
+}
+
+// BySubject counts requests per authenticated subject, as returned by subject from the request context.
+func BySubject(subject func(ctx context.Context) string) KeyExtractor {
+	return KeyExtractor{
+		Grpc: subject,
+		Http: func(c *gin.Context) string {
+			return subject(c.Request.Context())
+		},
+	}
+}
+
+func firstMetadata(ctx context.Context, name string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
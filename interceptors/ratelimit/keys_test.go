@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func grpcContext(peerAddr string, forwardedFor ...string) context.Context {
+	addr, err := net.ResolveTCPAddr("tcp", peerAddr)
+	if err != nil {
+		panic(err)
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	if len(forwardedFor) > 0 {
+		md := metadata.MD{}
+		md.Append("x-forwarded-for", forwardedFor...)
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+	return ctx
+}
+
+func TestByClientIPGrpc(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		peer           string
+		forwardedFor   []string
+		want           string
+	}{
+		{
+			name: "peer address without header",
+			peer: "203.0.113.7:51234",
+			want: "203.0.113.7",
+		},
+		{
+			name:         "spoofed header from untrusted peer",
+			peer:         "203.0.113.7:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			want:         "203.0.113.7",
+		},
+		{
+			name:           "spoofed header from peer outside trusted proxies",
+			trustedProxies: []string{"10.0.0.0/8"},
+			peer:           "203.0.113.7:51234",
+			forwardedFor:   []string{"198.51.100.1"},
+			want:           "203.0.113.7",
+		},
+		{
+			name:           "header from trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			peer:           "10.1.2.3:51234",
+			forwardedFor:   []string{"198.51.100.1"},
+			want:           "198.51.100.1",
+		},
+		{
+			name:           "client prepending a spoofed entry behind trusted proxies",
+			trustedProxies: []string{"10.0.0.0/8", "192.0.2.10"},
+			peer:           "10.1.2.3:51234",
+			forwardedFor:   []string{"198.51.100.1, 203.0.113.9", "192.0.2.10"},
+			want:           "203.0.113.9",
+		},
+		{
+			name:           "trusted proxy without header",
+			trustedProxies: []string{"10.1.2.3"},
+			peer:           "10.1.2.3:51234",
+			want:           "10.1.2.3",
+		},
+		{
+			name:           "only trusted proxies in header",
+			trustedProxies: []string{"10.0.0.0/8"},
+			peer:           "10.1.2.3:51234",
+			forwardedFor:   []string{"10.9.9.9"},
+			want:           "10.9.9.9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ByClientIP(tt.trustedProxies...).Grpc(grpcContext(tt.peer, tt.forwardedFor...))
+			if got != tt.want {
+				t.Errorf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestByClientIPSpoofedHeaderKeepsBucket(t *testing.T) {
+	extractor := ByClientIP()
+	first := extractor.Grpc(grpcContext("203.0.113.7:51234", "198.51.100.1"))
+	second := extractor.Grpc(grpcContext("203.0.113.7:51234", "198.51.100.2"))
+	if first != second {
+		t.Errorf("spoofed x-forwarded-for changed the key from %q to %q", first, second)
+	}
+}
+
+func TestByClientIPInvalidTrustedProxy(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an invalid trusted proxy")
+		}
+	}()
+	ByClientIP("not-an-ip")
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+// Config declares the rate of every method, by full gRPC method ("/pkg.Service/Method")
+// or by gin route ("GET /users/:id"). Methods without a rate use Default, unlimited when nil.
+//
+// Example:
+//
+//	default:
+//	  requests: 100
+//	  per: 1s
+//	methods:
+//	  /payment.PaymentService/Charge:
+//	    requests: 10
+//	    per: 1m
+//	    burst: 2
+type Config struct {
+	Default *Rate           `yaml:"default"`
+	Methods map[string]Rate `yaml:"methods"`
+}
+
+func (c *Config) rateFor(method string) (Rate, bool) {
+	if rate, ok := c.Methods[method]; ok {
+		return rate, !rate.unlimited()
+	}
+	if c.Default != nil {
+		return *c.Default, !c.Default.unlimited()
+	}
+	return Rate{}, false
+}
+
+var (
+	defaultOptions = &options{
+		config:       &Config{},
+		keyExtractor: ByClientIP(),
+	}
+)
+
+type options struct {
+	config       *Config
+	store        Store
+	keyExtractor KeyExtractor
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	for _, o := range opts {
+		o(optCopy)
+	}
+	if optCopy.store == nil {
+		optCopy.store = NewMemoryStore()
+	}
+	return optCopy
+}
+
+type Option func(*options)
+
+// WithConfig sets the rate of every method.
+func WithConfig(config *Config) Option {
+	return func(o *options) {
+		if config != nil {
+			o.config = config
+		}
+	}
+}
+
+// WithStore keeps the buckets in store instead of in memory.
+func WithStore(store Store) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// WithTrustedProxies counts gRPC requests per client IP reported in "x-forwarded-for" by the given
+// proxy IPs or CIDRs, see ByClientIP. The peer address is used by default.
+func WithTrustedProxies(proxies ...string) Option {
+	return func(o *options) {
+		o.keyExtractor = ByClientIP(proxies...)
+	}
+}
+
+// WithKeyExtractor sets who requests are counted against, the client IP by default.
+func WithKeyExtractor(keyExtractor KeyExtractor) Option {
+	return func(o *options) {
+		o.keyExtractor = keyExtractor
+	}
+}
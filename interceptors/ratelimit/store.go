@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Rate allows Requests every Per, with bursts of up to Burst requests (Requests by default).
+// A Rate without requests is unlimited.
+type Rate struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+	Burst    int           `yaml:"burst"`
+}
+
+func (r Rate) unlimited() bool {
+	return r.Requests <= 0 || r.Per <= 0
+}
+
+func (r Rate) capacity() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return float64(r.Requests)
+}
+
+// tokensPerSecond is the refill speed of the bucket.
+func (r Rate) tokensPerSecond() float64 {
+	return float64(r.Requests) / r.Per.Seconds()
+}
+
+// Result describes the state of a bucket after a request was counted.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets. Implement it on a shared backend (e.g. Redis) to enforce
+// limits across replicas.
+type Store interface {
+	// Take consumes one token from the bucket of key.
+	Take(ctx context.Context, key string, rate Rate) (Result, error)
+}
+
+type bucket struct {
+	tokens    float64
+	last      time.Time
+	capacity  float64
+	perSecond float64
+}
+
+func (b *bucket) refill(now time.Time) float64 {
+	return math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.perSecond)
+}
+
+// MemoryStore keeps the buckets of a single process.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// sweepInterval is how often buckets that refilled completely are dropped.
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), lastSweep: time.Now(), now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, rate Rate) (Result, error) {
+	now := s.now()
+	capacity, perSecond := rate.capacity(), rate.tokensPerSecond()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+	// a rate changed by configuration applies from now on
+	b.capacity, b.perSecond = capacity, perSecond
+	b.tokens = b.refill(now)
+	b.last = now
+
+	result := Result{Limit: int(capacity)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / perSecond)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((capacity - b.tokens) / perSecond)
+	return result, nil
+}
+
+// sweep drops the buckets that would be full by now, they are equivalent to a missing one.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if b.refill(now) >= b.capacity {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source for MemoryStore.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	store.lastSweep = clock.now
+	return store, clock
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    Rate
+		allowed int
+	}{
+		{name: "burst defaults to requests", rate: Rate{Requests: 5, Per: time.Second}, allowed: 5},
+		{name: "burst above requests", rate: Rate{Requests: 2, Per: time.Second, Burst: 4}, allowed: 4},
+		{name: "burst below requests", rate: Rate{Requests: 10, Per: time.Second, Burst: 3}, allowed: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := newTestStore()
+			for i := 0; i < tt.allowed; i++ {
+				result, err := store.Take(context.Background(), "k", tt.rate)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !result.Allowed {
+					t.Fatalf("request %d rejected within the burst", i+1)
+				}
+				if want := tt.allowed - i - 1; result.Remaining != want {
+					t.Errorf("request %d: remaining = %d, want %d", i+1, result.Remaining, want)
+				}
+				if result.Limit != tt.allowed {
+					t.Errorf("limit = %d, want %d", result.Limit, tt.allowed)
+				}
+			}
+			result, _ := store.Take(context.Background(), "k", tt.rate)
+			if result.Allowed {
+				t.Fatal("request over the burst allowed")
+			}
+			if want := tt.rate.Per / time.Duration(tt.rate.Requests); result.RetryAfter != want {
+				t.Errorf("retry after = %s, want %s", result.RetryAfter, want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	rate := Rate{Requests: 10, Per: time.Second}
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		allowed int
+	}{
+		{name: "no time elapsed", elapsed: 0, allowed: 0},
+		{name: "less than one token", elapsed: 50 * time.Millisecond, allowed: 0},
+		{name: "one token", elapsed: 100 * time.Millisecond, allowed: 1},
+		{name: "partial refill", elapsed: 350 * time.Millisecond, allowed: 3},
+		{name: "capped at capacity", elapsed: time.Hour, allowed: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, clock := newTestStore()
+			for i := 0; i < rate.Requests; i++ {
+				_, _ = store.Take(context.Background(), "k", rate)
+			}
+			clock.Advance(tt.elapsed)
+			allowed := 0
+			for {
+				result, _ := store.Take(context.Background(), "k", rate)
+				if !result.Allowed {
+					break
+				}
+				allowed++
+			}
+			if allowed != tt.allowed {
+				t.Errorf("allowed %d requests after %s, want %d", allowed, tt.elapsed, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store, _ := newTestStore()
+	rate := Rate{Requests: 1, Per: time.Minute}
+	if result, _ := store.Take(context.Background(), "a", rate); !result.Allowed {
+		t.Fatal("first request of a rejected")
+	}
+	if result, _ := store.Take(context.Background(), "a", rate); result.Allowed {
+		t.Fatal("second request of a allowed")
+	}
+	if result, _ := store.Take(context.Background(), "b", rate); !result.Allowed {
+		t.Fatal("first request of b rejected")
+	}
+}
+
+func TestMemoryStoreReset(t *testing.T) {
+	store, _ := newTestStore()
+	rate := Rate{Requests: 4, Per: 4 * time.Second}
+	result, _ := store.Take(context.Background(), "k", rate)
+	if result.Reset != time.Second {
+		t.Errorf("reset = %s, want 1s", result.Reset)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store, clock := newTestStore()
+	rate := Rate{Requests: 1, Per: time.Second}
+	_, _ = store.Take(context.Background(), "stale", rate)
+	clock.Advance(2 * sweepInterval)
+	_, _ = store.Take(context.Background(), "fresh", rate)
+	if _, ok := store.buckets["stale"]; ok {
+		t.Error("full bucket not swept")
+	}
+	if _, ok := store.buckets["fresh"]; !ok {
+		t.Error("bucket in use swept")
+	}
+}
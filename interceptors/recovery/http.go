@@ -0,0 +1,70 @@
+package recovery
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	uranuserrors "github.com/tqhuy-dev/xgen-uranus/errors"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+)
+
+// HttpRecovery is a Gin middleware for panic recovery, replacing the recovery of gin.Default.
+// The panic is logged through zap with its stack trace and correlation ID, counted and reported, and the
+// request is aborted with a 500 errors.HttpBody with the PanicReason and the error ID of the logs in its
+// metadata. The recovery handler receives the *gin.Context, the message of the error it returns is sent
+// as the "details" metadata of the body.
+func HttpRecovery(opts ...Option) gin.HandlerFunc {
+	o := evaluateOptions(opts)
+	return func(c *gin.Context) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				// http.ErrAbortHandler aborts the response on purpose, let net/http handle it
+				panic(p)
+			}
+			errorId := o.handlePanic(c, p, c.Request.Method+" "+c.FullPath(), reqctx.CorrelationId(c))
+
+			if brokenPipe(p) {
+				// the client is gone, the response cannot be written
+				_ = c.Error(fmt.Errorf("%v", p))
+				c.Abort()
+				return
+			}
+
+			metadata := map[string]string{"error_id": errorId}
+			if o.recoveryHandlerFunc != nil {
+				if err := o.recoveryHandlerFunc(c, p); err != nil {
+					metadata["details"] = err.Error()
+				}
+			}
+			uranuserrors.HttpAbort(c, uranuserrors.InternalServer(PanicReason, internalMessage(errorId)).WithMetadata(metadata))
+		}()
+		c.Next()
+	}
+}
+
+// brokenPipe reports whether p is a write error on a connection closed by the client.
+func brokenPipe(p interface{}) bool {
+	err, ok := p.(error)
+	if !ok {
+		return false
+	}
+	var netErr *net.OpError
+	if !errors.As(err, &netErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if !errors.As(netErr, &syscallErr) {
+		return false
+	}
+	message := strings.ToLower(syscallErr.Error())
+	return strings.Contains(message, "broken pipe") || strings.Contains(message, "connection reset by peer")
+}
@@ -0,0 +1,118 @@
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	uranuserrors "github.com/tqhuy-dev/xgen-uranus/errors"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func panicRouter(opts ...Option) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(reqctx.WithMeta(c.Request.Context(), reqctx.RequestMeta{CorrelationId: "corr-1"}))
+	}, HttpRecovery(opts...))
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return r
+}
+
+func TestHttpRecovery(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantDetails string
+	}{
+		{name: "default body"},
+		{
+			name: "recovery handler details",
+			opts: []Option{WithRecoveryHandlerContext(func(ctx context.Context, p interface{}) error {
+				return errors.New("handled " + p.(string))
+			})},
+			wantDetails: "handled boom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.ErrorLevel)
+			r := panicRouter(append(tt.opts, WithZapLog(zap.New(core)), WithStackTrace(false))...)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want 500", w.Code)
+			}
+			var body uranuserrors.HttpBody
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s: %v", w.Body.String(), err)
+			}
+			if body.Code != http.StatusInternalServerError || body.Reason != PanicReason {
+				t.Errorf("body = %+v, want a 500 %s error", body, PanicReason)
+			}
+			if body.Metadata["details"] != tt.wantDetails {
+				t.Errorf("details = %q, want %q", body.Metadata["details"], tt.wantDetails)
+			}
+
+			entries := logs.FilterMessage("recovered from panic").All()
+			if len(entries) != 1 {
+				t.Fatalf("logged %d panics, want 1", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["error_id"] == "" || fields["error_id"] != body.Metadata["error_id"] {
+				t.Errorf("logged error id = %v, body error id = %q", fields["error_id"], body.Metadata["error_id"])
+			}
+			if fields["correlation_id"] != "corr-1" {
+				t.Errorf("logged correlation id = %v, want corr-1", fields["correlation_id"])
+			}
+			if fields["method"] != "GET /panic" {
+				t.Errorf("logged method = %v, want GET /panic", fields["method"])
+			}
+		})
+	}
+}
+
+func TestHttpRecoveryRepanicsAbortHandler(t *testing.T) {
+	r := gin.New()
+	r.Use(HttpRecovery(WithZapLog(zap.NewNop())))
+	r.GET("/abort", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", p)
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+}
+
+func TestRecovery(t *testing.T) {
+	interceptor := Recovery(WithZapLog(zap.NewNop()), WithStackTrace(false))
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUser"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("code = %s, want Internal", status.Code(err))
+	}
+	e := uranuserrors.FromError(err)
+	if e.Reason != PanicReason || e.Metadata["error_id"] == "" {
+		t.Errorf("error = %+v, want the %s reason and an error id", e, PanicReason)
+	}
+}
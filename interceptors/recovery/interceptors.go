@@ -2,12 +2,23 @@ package recovery
 
 import (
 	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
 
+	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"github.com/tqhuy-dev/xgen/utilities"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// PanicReason is the reason of the errors returned for recovered panics.
+const PanicReason = "PANIC"
+
 // HandlerFunc is a function that recovers from the panic `p` by returning an `error`.
 type HandlerFunc func(p interface{}) (err error)
 
@@ -16,6 +27,8 @@ type HandlerFunc func(p interface{}) (err error)
 type HandlerFuncContext func(ctx context.Context, p interface{}) (err error)
 
 // Recovery returns a new unary server interceptor for panic recovery.
+// Panics are logged, counted and reported, and the client gets a codes.Internal status with a generic
+// message and the error ID of the logs, unless a recovery handler is set.
 func Recovery(opts ...Option) grpc.UnaryServerInterceptor {
 	o := evaluateOptions(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (_ interface{}, err error) {
@@ -23,7 +36,7 @@ func Recovery(opts ...Option) grpc.UnaryServerInterceptor {
 
 		defer func() {
 			if r := recover(); r != nil || panicked {
-				err = recoverFrom(ctx, r, o.recoveryHandlerFunc)
+				err = o.recoverFrom(ctx, r, info.FullMethod)
 			}
 		}()
 
@@ -41,7 +54,7 @@ func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 
 		defer func() {
 			if r := recover(); r != nil || panicked {
-				err = recoverFrom(stream.Context(), r, o.recoveryHandlerFunc)
+				err = o.recoverFrom(stream.Context(), r, info.FullMethod)
 			}
 		}()
 
@@ -51,9 +64,63 @@ func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 	}
 }
 
-func recoverFrom(ctx context.Context, p interface{}, r HandlerFuncContext) error {
-	if r == nil {
-		return status.Errorf(codes.Internal, "%v", p)
+func (o *options) recoverFrom(ctx context.Context, p interface{}, method string) error {
+	correlationId := reqctx.CorrelationId(ctx)
+	errorId := o.handlePanic(ctx, p, method, correlationId)
+	if o.recoveryHandlerFunc != nil {
+		return o.recoveryHandlerFunc(ctx, p)
+	}
+	s := status.New(codes.Internal, internalMessage(errorId))
+	withDetails, err := s.WithDetails(&errdetails.ErrorInfo{
+		Reason:   PanicReason,
+		Metadata: map[string]string{"error_id": errorId},
+	})
+	if err != nil {
+		return s.Err()
+	}
+	return withDetails.Err()
+}
+
+// internalMessage is the message of the errors returned for the panic errorId.
+func internalMessage(errorId string) string {
+	return fmt.Sprintf("internal error, error id: %s", errorId)
+}
+
+// handlePanic logs, counts and reports the panic p, and returns its error ID.
+func (o *options) handlePanic(ctx context.Context, p interface{}, method string, correlationId string) string {
+	errorId := utilities.GenerateUUIDV7()
+	var stack []byte
+	if o.stackTrace {
+		stack = debug.Stack()
+	}
+	fields := []zap.Field{
+		zap.String("error_id", errorId),
+		zap.String("panic", fmt.Sprint(p)),
+		zap.String("method", method),
+		zap.String(common.LogKeyAppName, o.appName),
+		zap.String("correlation_id", correlationId),
+	}
+	if stack != nil {
+		fields = append(fields, zap.ByteString("stack", stack))
+	}
+	o.zapLog.Error("recovered from panic", fields...)
+
+	if o.panicCounter != nil {
+		o.panicCounter.Inc()
+	}
+	if o.reporter != nil {
+		err := o.reporter.Report(ctx, Report{
+			ErrorId:       errorId,
+			Time:          time.Now(),
+			AppName:       o.appName,
+			Method:        method,
+			CorrelationId: correlationId,
+			Panic:         fmt.Sprint(p),
+			Stack:         string(stack),
+		})
+		if err != nil {
+			o.zapLog.Warn("failed to report panic", zap.String("error_id", errorId), zap.Error(err))
+		}
 	}
-	return r(ctx, p)
+	return errorId
 }
@@ -2,16 +2,25 @@ package recovery
 
 import (
 	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 )
 
 var (
 	defaultOptions = &options{
 		recoveryHandlerFunc: nil,
+		stackTrace:          true,
 	}
 )
 
 type options struct {
 	recoveryHandlerFunc HandlerFuncContext
+	zapLog              *zap.Logger
+	appName             string
+	stackTrace          bool
+	panicCounter        prometheus.Counter
+	reporter            Reporter
 }
 
 func evaluateOptions(opts []Option) *options {
@@ -20,6 +29,9 @@ func evaluateOptions(opts []Option) *options {
 	for _, o := range opts {
 		o(optCopy)
 	}
+	if optCopy.zapLog == nil {
+		optCopy.zapLog, _ = zap.NewProduction()
+	}
 	return optCopy
 }
 
@@ -40,3 +52,38 @@ func WithRecoveryHandlerContext(f HandlerFuncContext) Option {
 		o.recoveryHandlerFunc = f
 	}
 }
+
+// WithZapLog sets the logger of the recovered panics.
+func WithZapLog(zapLog *zap.Logger) Option {
+	return func(o *options) {
+		o.zapLog = zapLog
+	}
+}
+
+// WithAppName sets the app name logged with the recovered panics.
+func WithAppName(appName string) Option {
+	return func(o *options) {
+		o.appName = appName
+	}
+}
+
+// WithStackTrace captures the stack trace of panics to log and report it, enabled by default.
+func WithStackTrace(enabled bool) Option {
+	return func(o *options) {
+		o.stackTrace = enabled
+	}
+}
+
+// WithPanicCounter increments counter on every recovered panic, see metrics.Metrics.PanicCounter.
+func WithPanicCounter(counter prometheus.Counter) Option {
+	return func(o *options) {
+		o.panicCounter = counter
+	}
+}
+
+// WithReporter forwards the recovered panics to reporter.
+func WithReporter(reporter Reporter) Option {
+	return func(o *options) {
+		o.reporter = reporter
+	}
+}
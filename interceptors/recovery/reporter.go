@@ -0,0 +1,58 @@
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Report describes a recovered panic.
+type Report struct {
+	ErrorId       string    `json:"error_id"`
+	Time          time.Time `json:"time"`
+	AppName       string    `json:"app_name,omitempty"`
+	Method        string    `json:"method"`
+	CorrelationId string    `json:"correlation_id,omitempty"`
+	Panic         string    `json:"panic"`
+	Stack         string    `json:"stack,omitempty"`
+}
+
+// Reporter forwards recovered panics to an error-tracking sink (e.g. Sentry).
+// Report is called synchronously from the recovering request, implementations should not block for long.
+type Reporter interface {
+	Report(ctx context.Context, report Report) error
+}
+
+// FileReporter appends the reports to a file, one JSON document per line. It is meant for local use.
+type FileReporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileReporter opens or creates the report file at path.
+func NewFileReporter(path string) (*FileReporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open panic report file %s: %w", path, err)
+	}
+	return &FileReporter{file: file}, nil
+}
+
+func (r *FileReporter) Report(_ context.Context, report Report) error {
+	line, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the report file.
+func (r *FileReporter) Close() error {
+	return r.file.Close()
+}
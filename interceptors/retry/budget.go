@@ -0,0 +1,50 @@
+package retry
+
+import "sync"
+
+// budget is a token bucket shared by every call of a client. A nil budget allows everything.
+type budget struct {
+	mu         sync.Mutex
+	maxTokens  float64
+	tokenRatio float64
+	tokens     float64
+}
+
+func newBudget(cfg *BudgetConfig) *budget {
+	if cfg == nil {
+		return nil
+	}
+	return &budget{
+		maxTokens:  cfg.MaxTokens,
+		tokenRatio: cfg.TokenRatio,
+		tokens:     cfg.MaxTokens,
+	}
+}
+
+// allow reports whether another attempt may be sent.
+func (b *budget) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens > b.maxTokens/2
+}
+
+func (b *budget) onFailure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = max(b.tokens-1, 0)
+}
+
+func (b *budget) onSuccess() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.tokenRatio, b.maxTokens)
+}
@@ -0,0 +1,56 @@
+package retry
+
+import "testing"
+
+func TestBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		successes int
+		wantAllow bool
+	}{
+		{name: "full budget", wantAllow: true},
+		{name: "failures under half", failures: 4, wantAllow: true},
+		{name: "exhausted at half", failures: 5, wantAllow: false},
+		{name: "tokens never go negative", failures: 100, successes: 50, wantAllow: false},
+		{name: "successes give tokens back", failures: 5, successes: 10, wantAllow: true},
+		{name: "successes capped at max tokens", successes: 1000, failures: 4, wantAllow: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBudget(&BudgetConfig{MaxTokens: 10, TokenRatio: 0.1})
+			for i := 0; i < tt.failures; i++ {
+				b.onFailure()
+			}
+			for i := 0; i < tt.successes; i++ {
+				b.onSuccess()
+			}
+			if got := b.allow(); got != tt.wantAllow {
+				t.Errorf("allow = %t (%v tokens), want %t", got, b.tokens, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestBudgetCapped(t *testing.T) {
+	b := newBudget(&BudgetConfig{MaxTokens: 10, TokenRatio: 0.1})
+	for i := 0; i < 1000; i++ {
+		b.onSuccess()
+	}
+	if b.tokens != 10 {
+		t.Errorf("tokens = %v, want 10", b.tokens)
+	}
+	b.onFailure()
+	if b.tokens != 9 {
+		t.Errorf("tokens = %v, want 9", b.tokens)
+	}
+}
+
+func TestNilBudgetAllowsEverything(t *testing.T) {
+	var b *budget
+	b.onFailure()
+	b.onSuccess()
+	if !b.allow() {
+		t.Error("nil budget denied an attempt")
+	}
+}
@@ -0,0 +1,139 @@
+package retry
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"google.golang.org/grpc/codes"
+)
+
+// Config declares the retry and hedging policies of an outbound client.
+//
+// Example:
+//
+//	budget:
+//	  max_tokens: 10
+//	  token_ratio: 0.1
+//	methods:
+//	  - name: "/user.UserService/GetUser"
+//	    hedging:
+//	      max_attempts: 3
+//	      delay: 50ms
+//	      non_fatal_codes: [UNAVAILABLE]
+//	  - name: "/user.UserService/*"
+//	    retry:
+//	      max_attempts: 3
+//	      initial_backoff: 100ms
+//	      max_backoff: 1s
+//	      backoff_multiplier: 2
+//	      retryable_codes: [UNAVAILABLE, RESOURCE_EXHAUSTED]
+type Config struct {
+	Budget  *BudgetConfig  `yaml:"budget"`
+	Methods []MethodPolicy `yaml:"methods"`
+}
+
+// BudgetConfig limits retries and hedges once too many attempts are failing, so that
+// retries do not amplify an outage. It follows the gRPC retry throttling semantics:
+// every failed attempt costs one token, every success gives back TokenRatio tokens and
+// new attempts are only allowed while more than half of MaxTokens remain.
+type BudgetConfig struct {
+	MaxTokens  float64 `yaml:"max_tokens"`
+	TokenRatio float64 `yaml:"token_ratio"`
+}
+
+// MethodPolicy binds a retry or hedging policy to a method.
+// Name is either a full method ("/pkg.Service/Method"), a whole service ("/pkg.Service/*") or "*".
+type MethodPolicy struct {
+	Name    string         `yaml:"name"`
+	Retry   *RetryPolicy   `yaml:"retry"`
+	Hedging *HedgingPolicy `yaml:"hedging"`
+}
+
+// RetryPolicy re-sends a failed call after an exponential backoff.
+type RetryPolicy struct {
+	MaxAttempts       int           `yaml:"max_attempts"`
+	InitialBackoff    time.Duration `yaml:"initial_backoff"`
+	MaxBackoff        time.Duration `yaml:"max_backoff"`
+	BackoffMultiplier float64       `yaml:"backoff_multiplier"`
+	PerAttemptTimeout time.Duration `yaml:"per_attempt_timeout"`
+	RetryableCodes    []string      `yaml:"retryable_codes"`
+}
+
+// HedgingPolicy sends up to MaxAttempts copies of the call, each Delay apart, and keeps the
+// first successful response. It must only be used for idempotent methods.
+type HedgingPolicy struct {
+	MaxAttempts   int           `yaml:"max_attempts"`
+	Delay         time.Duration `yaml:"delay"`
+	NonFatalCodes []string      `yaml:"non_fatal_codes"`
+}
+
+// LoadConfig reads a retry configuration from a YAML file.
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retry config %s: %w", path, err)
+	}
+	cfg := &Config{}
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse retry config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that every policy is usable.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Budget != nil && (c.Budget.MaxTokens <= 0 || c.Budget.TokenRatio <= 0) {
+		return fmt.Errorf("retry budget requires positive max_tokens and token_ratio")
+	}
+	for _, m := range c.Methods {
+		if m.Name == "" {
+			return fmt.Errorf("retry policy requires a method name")
+		}
+		if m.Retry != nil && m.Hedging != nil {
+			return fmt.Errorf("method %s: retry and hedging policies are mutually exclusive", m.Name)
+		}
+		if m.Retry != nil {
+			if m.Retry.MaxAttempts < 2 {
+				return fmt.Errorf("method %s: retry max_attempts must be at least 2", m.Name)
+			}
+			if m.Retry.InitialBackoff <= 0 || m.Retry.MaxBackoff < m.Retry.InitialBackoff {
+				return fmt.Errorf("method %s: retry backoff must satisfy 0 < initial_backoff <= max_backoff", m.Name)
+			}
+			if _, err := parseCodes(m.Retry.RetryableCodes); err != nil {
+				return fmt.Errorf("method %s: %w", m.Name, err)
+			}
+		}
+		if m.Hedging != nil {
+			if m.Hedging.MaxAttempts < 2 {
+				return fmt.Errorf("method %s: hedging max_attempts must be at least 2", m.Name)
+			}
+			if _, err := parseCodes(m.Hedging.NonFatalCodes); err != nil {
+				return fmt.Errorf("method %s: %w", m.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseCodes converts names such as "UNAVAILABLE" or "unavailable" to a set of codes.
+func parseCodes(names []string) (map[codes.Code]struct{}, error) {
+	set := make(map[codes.Code]struct{}, len(names))
+	for _, name := range names {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(name)))); err != nil {
+			return nil, fmt.Errorf("unknown status code %q", name)
+		}
+		set[code] = struct{}{}
+	}
+	return set, nil
+}
@@ -0,0 +1,181 @@
+package retry
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// previousAttemptsKey is the standard gRPC header telling the server how many attempts preceded this one.
+const previousAttemptsKey = "grpc-previous-rpc-attempts"
+
+// UnaryClientInterceptor returns a new unary client interceptor applying the configured
+// retry and hedging policies. Methods without a policy are invoked once.
+// It panics if the configuration is invalid, use Config.Validate to check it beforehand.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := evaluateOptions(opts)
+	if err := o.config.Validate(); err != nil {
+		panic(err)
+	}
+	r := &retrier{
+		options:  o,
+		policies: newPolicyTable(o.config),
+		budget:   newBudget(o.config.Budget),
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy := r.policies.lookup(method)
+		switch {
+		case policy == nil:
+			return invoker(ctx, method, req, reply, cc, opts...)
+		case policy.hedging != nil:
+			if replyMsg, ok := reply.(proto.Message); ok {
+				return r.hedge(ctx, policy.hedging, method, req, replyMsg, cc, invoker, opts...)
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		default:
+			return r.retry(ctx, policy.retry, method, req, reply, cc, invoker, opts...)
+		}
+	}
+}
+
+type retrier struct {
+	*options
+	policies *policyTable
+	budget   *budget
+}
+
+func (r *retrier) retry(ctx context.Context, policy *retryPolicy, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	for attempt := 1; ; attempt++ {
+		err := r.invokeAttempt(ctx, policy.perAttemptTimeout, attempt, method, req, reply, cc, invoker, opts...)
+		if err == nil {
+			r.budget.onSuccess()
+			return nil
+		}
+		code := status.Code(err)
+		if !policy.retryable(code) || ctx.Err() != nil {
+			return err
+		}
+		r.budget.onFailure()
+		if attempt >= policy.maxAttempts {
+			r.log(ctx, "retry attempts exhausted", method, attempt, err)
+			return err
+		}
+		if !r.budget.allow() {
+			r.log(ctx, "retry budget exhausted", method, attempt, err)
+			return err
+		}
+
+		backoff := policy.backoff(attempt)
+		r.log(ctx, "retrying call", method, attempt, err, zap.Duration("backoff", backoff))
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *retrier) invokeAttempt(ctx context.Context, timeout time.Duration, attempt int, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if attempt > 1 {
+		ctx = metadata.AppendToOutgoingContext(ctx, previousAttemptsKey, strconv.Itoa(attempt-1))
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+type hedgeResult struct {
+	attempt int
+	reply   proto.Message
+	err     error
+}
+
+// hedge runs concurrent attempts into clones of reply, so that late responses never race
+// with the one that is returned to the caller.
+func (r *retrier) hedge(ctx context.Context, policy *hedgingPolicy, method string, req interface{}, reply proto.Message, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, policy.maxAttempts)
+	launched, pending := 0, 0
+	launch := func() {
+		launched++
+		pending++
+		attempt, attemptReply := launched, proto.Clone(reply)
+		go func() {
+			err := r.invokeAttempt(hedgeCtx, 0, attempt, method, req, attemptReply, cc, invoker, opts...)
+			results <- hedgeResult{attempt: attempt, reply: attemptReply, err: err}
+		}()
+	}
+	canHedge := func() bool {
+		return launched < policy.maxAttempts && r.budget.allow()
+	}
+
+	launch()
+	timer := time.NewTimer(policy.delay)
+	defer timer.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				r.budget.onSuccess()
+				proto.Reset(reply)
+				proto.Merge(reply, res.reply)
+				return nil
+			}
+			lastErr = res.err
+			if !policy.nonFatal(status.Code(res.err)) {
+				return res.err
+			}
+			r.budget.onFailure()
+			if canHedge() {
+				r.log(ctx, "hedging call after failure", method, res.attempt, res.err)
+				launch()
+				timer.Reset(policy.delay)
+			} else if pending == 0 {
+				r.log(ctx, "hedging attempts exhausted", method, res.attempt, res.err)
+				return lastErr
+			}
+		case <-timer.C:
+			if canHedge() {
+				r.log(ctx, "hedging call after delay", method, launched, nil)
+				launch()
+				timer.Reset(policy.delay)
+			}
+		case <-ctx.Done():
+			if lastErr != nil {
+				return lastErr
+			}
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+func (r *retrier) log(ctx context.Context, msg string, method string, attempt int, err error, extra ...zap.Field) {
+	correlationId := reqctx.CorrelationId(ctx)
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.Int("attempt", attempt),
+		zap.String("app_name", r.appName),
+		zap.String("correlation_id", correlationId),
+	}
+	if err != nil {
+		fields = append(fields, zap.String("code", status.Code(err).String()), zap.Error(err))
+	}
+	r.zapLog.Warn(msg, append(fields, extra...)...)
+}
@@ -0,0 +1,241 @@
+package retry
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const testMethod = "/user.UserService/GetUser"
+
+func retryConfig(budget *BudgetConfig) *Config {
+	return &Config{
+		Budget: budget,
+		Methods: []MethodPolicy{{
+			Name: testMethod,
+			Retry: &RetryPolicy{
+				MaxAttempts:       3,
+				InitialBackoff:    time.Millisecond,
+				MaxBackoff:        time.Millisecond,
+				BackoffMultiplier: 2,
+				RetryableCodes:    []string{"UNAVAILABLE"},
+			},
+		}},
+	}
+}
+
+// attemptOf returns the attempt number of a call from its grpc-previous-rpc-attempts metadata.
+func attemptOf(ctx context.Context) int {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	values := md.Get(previousAttemptsKey)
+	if len(values) == 0 {
+		return 1
+	}
+	previous, _ := strconv.Atoi(values[len(values)-1])
+	return previous + 1
+}
+
+// failingInvoker fails the first failures calls with code, then succeeds.
+func failingInvoker(calls *atomic.Int64, failures int64, code codes.Code) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if calls.Add(1) <= failures {
+			return status.Error(code, "failed")
+		}
+		return nil
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int64
+		code      codes.Code
+		wantCode  codes.Code
+		wantCalls int64
+	}{
+		{name: "success without retry", failures: 0, code: codes.Unavailable, wantCode: codes.OK, wantCalls: 1},
+		{name: "success after retries", failures: 2, code: codes.Unavailable, wantCode: codes.OK, wantCalls: 3},
+		{name: "attempts exhausted", failures: 5, code: codes.Unavailable, wantCode: codes.Unavailable, wantCalls: 3},
+		{name: "non-retryable code", failures: 5, code: codes.InvalidArgument, wantCode: codes.InvalidArgument, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := UnaryClientInterceptor(WithConfig(retryConfig(nil)), WithZapLog(zap.NewNop()))
+			calls := &atomic.Int64{}
+			err := interceptor(context.Background(), testMethod, nil, nil, nil, failingInvoker(calls, tt.failures, tt.code))
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("code = %s, want %s", status.Code(err), tt.wantCode)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryPreviousAttemptsHeader(t *testing.T) {
+	interceptor := UnaryClientInterceptor(WithConfig(retryConfig(nil)), WithZapLog(zap.NewNop()))
+	var attempts []int
+	_ = interceptor(context.Background(), testMethod, nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempts = append(attempts, attemptOf(ctx))
+		return status.Error(codes.Unavailable, "failed")
+	})
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[1] != 2 || attempts[2] != 3 {
+		t.Errorf("attempts = %v, want [1 2 3]", attempts)
+	}
+}
+
+func TestRetryBudgetExhaustion(t *testing.T) {
+	interceptor := UnaryClientInterceptor(
+		WithConfig(retryConfig(&BudgetConfig{MaxTokens: 4, TokenRatio: 1})),
+		WithZapLog(zap.NewNop()))
+	calls := &atomic.Int64{}
+	alwaysFailing := failingInvoker(calls, 1000, codes.Unavailable)
+
+	// 4 tokens: the first call fails twice (2 tokens left), retries stop at half of the budget
+	_ = interceptor(context.Background(), testMethod, nil, nil, nil, alwaysFailing)
+	if calls.Load() != 2 {
+		t.Fatalf("first call attempts = %d, want 2", calls.Load())
+	}
+	calls.Store(0)
+	_ = interceptor(context.Background(), testMethod, nil, nil, nil, alwaysFailing)
+	if calls.Load() != 1 {
+		t.Fatalf("attempts with exhausted budget = %d, want 1", calls.Load())
+	}
+
+	// successes refill the budget
+	for i := 0; i < 4; i++ {
+		_ = interceptor(context.Background(), testMethod, nil, nil, nil, failingInvoker(&atomic.Int64{}, 0, codes.OK))
+	}
+	calls.Store(0)
+	_ = interceptor(context.Background(), testMethod, nil, nil, nil, alwaysFailing)
+	if calls.Load() != 2 {
+		t.Errorf("attempts after refill = %d, want 2", calls.Load())
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	cfg := retryConfig(nil)
+	cfg.Methods[0].Retry.InitialBackoff = time.Hour
+	cfg.Methods[0].Retry.MaxBackoff = time.Hour
+	interceptor := UnaryClientInterceptor(WithConfig(cfg), WithZapLog(zap.NewNop()))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	calls := &atomic.Int64{}
+	err := interceptor(ctx, testMethod, nil, nil, nil, failingInvoker(calls, 1000, codes.Unavailable))
+	if status.Code(err) != codes.Unavailable || calls.Load() != 1 {
+		t.Errorf("code = %s after %d calls, want Unavailable after 1", status.Code(err), calls.Load())
+	}
+}
+
+func TestUnconfiguredMethodInvokedOnce(t *testing.T) {
+	interceptor := UnaryClientInterceptor(WithConfig(retryConfig(nil)), WithZapLog(zap.NewNop()))
+	calls := &atomic.Int64{}
+	_ = interceptor(context.Background(), "/user.UserService/DeleteUser", nil, nil, nil, failingInvoker(calls, 1000, codes.Unavailable))
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func hedgingConfig(delay time.Duration) *Config {
+	return &Config{Methods: []MethodPolicy{{
+		Name: testMethod,
+		Hedging: &HedgingPolicy{
+			MaxAttempts:   3,
+			Delay:         delay,
+			NonFatalCodes: []string{"UNAVAILABLE"},
+		},
+	}}}
+}
+
+func TestHedgingCancelsLosers(t *testing.T) {
+	interceptor := UnaryClientInterceptor(WithConfig(hedgingConfig(10*time.Millisecond)), WithZapLog(zap.NewNop()))
+	reply := wrapperspb.String("")
+
+	var mu sync.Mutex
+	replies := make(map[interface{}]int)
+	firstCancelled := make(chan struct{})
+	invoker := func(ctx context.Context, method string, req, attemptReply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempt := attemptOf(ctx)
+		mu.Lock()
+		replies[attemptReply] = attempt
+		mu.Unlock()
+		if attempt == 1 {
+			// the slow first attempt only returns once cancelled by the winner
+			<-ctx.Done()
+			close(firstCancelled)
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		attemptReply.(*wrapperspb.StringValue).Value = "attempt " + strconv.Itoa(attempt)
+		return nil
+	}
+
+	if err := interceptor(context.Background(), testMethod, nil, reply, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if reply.GetValue() != "attempt 2" {
+		t.Errorf("reply = %q, want the response of attempt 2", reply.GetValue())
+	}
+	select {
+	case <-firstCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("losing attempt not cancelled")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(replies) != 2 {
+		t.Errorf("attempts = %d, want 2", len(replies))
+	}
+	for attemptReply, attempt := range replies {
+		if attemptReply == interface{}(reply) {
+			t.Errorf("attempt %d wrote into the reply of the caller instead of a clone", attempt)
+		}
+	}
+}
+
+func TestHedging(t *testing.T) {
+	tests := []struct {
+		name      string
+		codes     []codes.Code
+		wantCode  codes.Code
+		wantCalls int64
+	}{
+		{name: "first attempt succeeds", codes: []codes.Code{codes.OK}, wantCode: codes.OK, wantCalls: 1},
+		{name: "non-fatal failure hedged", codes: []codes.Code{codes.Unavailable, codes.OK}, wantCode: codes.OK, wantCalls: 2},
+		{name: "fatal failure returned", codes: []codes.Code{codes.PermissionDenied}, wantCode: codes.PermissionDenied, wantCalls: 1},
+		{
+			name:      "attempts exhausted",
+			codes:     []codes.Code{codes.Unavailable, codes.Unavailable, codes.Unavailable},
+			wantCode:  codes.Unavailable,
+			wantCalls: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a long delay so that attempts are only hedged after failures
+			interceptor := UnaryClientInterceptor(WithConfig(hedgingConfig(time.Hour)), WithZapLog(zap.NewNop()))
+			calls := &atomic.Int64{}
+			err := interceptor(context.Background(), testMethod, nil, wrapperspb.String(""), nil,
+				func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+					n := calls.Add(1)
+					return status.Error(tt.codes[n-1], "attempt")
+				})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("code = %s, want %s", status.Code(err), tt.wantCode)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
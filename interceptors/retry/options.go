@@ -0,0 +1,54 @@
+package retry
+
+import (
+	"go.uber.org/zap"
+)
+
+var (
+	defaultOptions = &options{
+		config: &Config{},
+	}
+)
+
+type options struct {
+	config  *Config
+	zapLog  *zap.Logger
+	appName string
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	for _, o := range opts {
+		o(optCopy)
+	}
+	if optCopy.zapLog == nil {
+		optCopy.zapLog, _ = zap.NewProduction()
+	}
+	return optCopy
+}
+
+type Option func(*options)
+
+// WithConfig sets the retry and hedging policies.
+func WithConfig(config *Config) Option {
+	return func(o *options) {
+		if config != nil {
+			o.config = config
+		}
+	}
+}
+
+// WithZapLog sets the logger used to report retries and hedges.
+func WithZapLog(zapLog *zap.Logger) Option {
+	return func(o *options) {
+		o.zapLog = zapLog
+	}
+}
+
+// WithAppName adds the app name to every retry log.
+func WithAppName(appName string) Option {
+	return func(o *options) {
+		o.appName = appName
+	}
+}
@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+type retryPolicy struct {
+	maxAttempts       int
+	initialBackoff    time.Duration
+	maxBackoff        time.Duration
+	backoffMultiplier float64
+	perAttemptTimeout time.Duration
+	retryableCodes    map[codes.Code]struct{}
+}
+
+// backoff returns the full-jitter delay to wait after the given failed attempt (starting at 1).
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.backoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	ceiling := float64(p.initialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	ceiling = math.Min(ceiling, float64(p.maxBackoff))
+	return time.Duration(rand.Float64() * ceiling)
+}
+
+func (p *retryPolicy) retryable(code codes.Code) bool {
+	_, ok := p.retryableCodes[code]
+	return ok
+}
+
+type hedgingPolicy struct {
+	maxAttempts   int
+	delay         time.Duration
+	nonFatalCodes map[codes.Code]struct{}
+}
+
+func (p *hedgingPolicy) nonFatal(code codes.Code) bool {
+	_, ok := p.nonFatalCodes[code]
+	return ok
+}
+
+type methodPolicy struct {
+	retry   *retryPolicy
+	hedging *hedgingPolicy
+}
+
+// policyTable resolves the policy of a full method name, preferring an exact
+// match, then a service wildcard, then the global default.
+type policyTable struct {
+	methods  map[string]*methodPolicy
+	services map[string]*methodPolicy
+	fallback *methodPolicy
+}
+
+func newPolicyTable(cfg *Config) *policyTable {
+	t := &policyTable{
+		methods:  make(map[string]*methodPolicy),
+		services: make(map[string]*methodPolicy),
+	}
+	for _, m := range cfg.Methods {
+		policy := &methodPolicy{}
+		if m.Retry != nil {
+			retryableCodes, _ := parseCodes(m.Retry.RetryableCodes)
+			policy.retry = &retryPolicy{
+				maxAttempts:       m.Retry.MaxAttempts,
+				initialBackoff:    m.Retry.InitialBackoff,
+				maxBackoff:        m.Retry.MaxBackoff,
+				backoffMultiplier: m.Retry.BackoffMultiplier,
+				perAttemptTimeout: m.Retry.PerAttemptTimeout,
+				retryableCodes:    retryableCodes,
+			}
+		}
+		if m.Hedging != nil {
+			nonFatalCodes, _ := parseCodes(m.Hedging.NonFatalCodes)
+			policy.hedging = &hedgingPolicy{
+				maxAttempts:   m.Hedging.MaxAttempts,
+				delay:         m.Hedging.Delay,
+				nonFatalCodes: nonFatalCodes,
+			}
+		}
+
+		switch {
+		case m.Name == "*":
+			t.fallback = policy
+		case strings.HasSuffix(m.Name, "/*"):
+			t.services[strings.TrimSuffix(m.Name, "*")] = policy
+		default:
+			t.methods[m.Name] = policy
+		}
+	}
+	return t
+}
+
+func (t *policyTable) lookup(fullMethod string) *methodPolicy {
+	if p, ok := t.methods[fullMethod]; ok {
+		return p
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		if p, ok := t.services[fullMethod[:i+1]]; ok {
+			return p
+		}
+	}
+	return t.fallback
+}
@@ -0,0 +1,97 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	policy := &retryPolicy{
+		initialBackoff:    100 * time.Millisecond,
+		maxBackoff:        time.Second,
+		backoffMultiplier: 2,
+	}
+	tests := []struct {
+		name        string
+		policy      *retryPolicy
+		attempt     int
+		wantCeiling time.Duration
+	}{
+		{name: "first attempt", policy: policy, attempt: 1, wantCeiling: 100 * time.Millisecond},
+		{name: "second attempt", policy: policy, attempt: 2, wantCeiling: 200 * time.Millisecond},
+		{name: "fourth attempt", policy: policy, attempt: 4, wantCeiling: 800 * time.Millisecond},
+		{name: "capped at max backoff", policy: policy, attempt: 10, wantCeiling: time.Second},
+		{
+			name:        "multiplier below one is constant",
+			policy:      &retryPolicy{initialBackoff: 100 * time.Millisecond, maxBackoff: time.Second, backoffMultiplier: 0.5},
+			attempt:     5,
+			wantCeiling: 100 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var maxSeen time.Duration
+			for i := 0; i < 2000; i++ {
+				backoff := tt.policy.backoff(tt.attempt)
+				if backoff < 0 || backoff >= tt.wantCeiling {
+					t.Fatalf("backoff %s outside [0, %s)", backoff, tt.wantCeiling)
+				}
+				maxSeen = max(maxSeen, backoff)
+			}
+			// full jitter spreads over the whole range, not only its lower part
+			if maxSeen < tt.wantCeiling/2 {
+				t.Errorf("largest backoff %s of 2000 draws below half of %s", maxSeen, tt.wantCeiling)
+			}
+		})
+	}
+}
+
+func TestPolicyLookup(t *testing.T) {
+	exact := &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	service := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	fallback := &RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	table := newPolicyTable(&Config{Methods: []MethodPolicy{
+		{Name: "/user.UserService/GetUser", Retry: exact},
+		{Name: "/user.UserService/*", Retry: service},
+		{Name: "*", Retry: fallback},
+	}})
+	tests := []struct {
+		method       string
+		wantAttempts int
+	}{
+		{method: "/user.UserService/GetUser", wantAttempts: 2},
+		{method: "/user.UserService/ListUsers", wantAttempts: 3},
+		{method: "/order.OrderService/GetOrder", wantAttempts: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			policy := table.lookup(tt.method)
+			if policy == nil || policy.retry == nil {
+				t.Fatal("no retry policy")
+			}
+			if policy.retry.maxAttempts != tt.wantAttempts {
+				t.Errorf("max attempts = %d, want %d", policy.retry.maxAttempts, tt.wantAttempts)
+			}
+		})
+	}
+	if policy := newPolicyTable(&Config{}).lookup("/user.UserService/GetUser"); policy != nil {
+		t.Error("policy found without configuration")
+	}
+}
+
+func TestParseCodes(t *testing.T) {
+	set, err := parseCodes([]string{"UNAVAILABLE", "resource_exhausted"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []codes.Code{codes.Unavailable, codes.ResourceExhausted} {
+		if _, ok := set[code]; !ok {
+			t.Errorf("%s not parsed", code)
+		}
+	}
+	if _, err := parseCodes([]string{"NOT_A_CODE"}); err == nil {
+		t.Error("unknown code accepted")
+	}
+}
@@ -0,0 +1,64 @@
+package interceptors
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// samplingTick is the period of zap.SamplingConfig, as in zap.Config.Build.
+const samplingTick = time.Second
+
+// requestSampler applies a zap.SamplingConfig to the request logs of each gRPC method or gin route:
+// the first Initial requests of every second are logged, then every Thereafter-th one.
+// Counting per method keeps the rare methods logged while the busy ones are sampled.
+type requestSampler struct {
+	config   zap.SamplingConfig
+	counters sync.Map // method -> *samplingCounter
+}
+
+type samplingCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+func newRequestSampler(config zap.SamplingConfig) *requestSampler {
+	return &requestSampler{config: config}
+}
+
+// incCheckReset counts a request at t, restarting the count on a new tick.
+func (c *samplingCounter) incCheckReset(t time.Time) uint64 {
+	tn := t.UnixNano()
+	resetAfter := c.resetAt.Load()
+	if resetAfter > tn {
+		return c.count.Add(1)
+	}
+	c.count.Store(1)
+	newResetAfter := tn + samplingTick.Nanoseconds()
+	if !c.resetAt.CompareAndSwap(resetAfter, newResetAfter) {
+		// another request reset the counter
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// sample reports whether the request log of method is kept. The Hook of the config is called
+// with the decision, as zap does for sampled entries.
+func (s *requestSampler) sample(method string, msg string, level zapcore.Level) bool {
+	now := time.Now()
+	value, _ := s.counters.LoadOrStore(method, &samplingCounter{})
+	n := value.(*samplingCounter).incCheckReset(now)
+	keep := n <= uint64(s.config.Initial) ||
+		(s.config.Thereafter > 0 && (n-uint64(s.config.Initial))%uint64(s.config.Thereafter) == 0)
+	if s.config.Hook != nil {
+		decision := zapcore.LogDropped
+		if keep {
+			decision = zapcore.LogSampled
+		}
+		s.config.Hook(zapcore.Entry{Level: level, Time: now, Message: msg}, decision)
+	}
+	return keep
+}
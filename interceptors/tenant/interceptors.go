@@ -0,0 +1,171 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/auth"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errMissingTenant = errors.New("missing tenant")
+	errInvalidTenant = errors.New("invalid tenant")
+	errTenantDenied  = errors.New("tenant not allowed")
+	errTenantClaim   = errors.New("tenant does not match the token")
+)
+
+// FromContext returns the tenant of the request handled with ctx, empty when it names none.
+func FromContext(ctx context.Context) string {
+	return reqctx.Tenant(ctx)
+}
+
+// tokenTenant returns the tenant claim of the JWT verified for the request handled with ctx,
+// empty when the request is anonymous or the check is disabled.
+func (o *options) tokenTenant(ctx context.Context) string {
+	if o.claim == "" {
+		return ""
+	}
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return claims.String(o.claim)
+}
+
+// resolve returns the tenant read by the first source naming one and checks it against method
+// and the tenant of the token of the request.
+func (o *options) resolve(ctx context.Context, method string, read func(Source) string) (string, error) {
+	tenant := ""
+	for _, source := range o.sources {
+		if tenant = read(source); tenant != "" {
+			break
+		}
+	}
+	claimed := o.tokenTenant(ctx)
+	switch {
+	case tenant == "" && o.requires(method):
+		return "", errMissingTenant
+	case tenant == "":
+		return "", nil
+	case !o.validator(tenant):
+		return "", errInvalidTenant
+	case claimed != "" && claimed != tenant:
+		return "", errTenantClaim
+	case !o.permits(method, tenant):
+		return "", errTenantDenied
+	}
+	return tenant, nil
+}
+
+func (o *options) resolveGrpc(ctx context.Context, method string) (context.Context, error) {
+	tenant, err := o.resolve(ctx, method, func(source Source) string {
+		if source.Grpc == nil {
+			return ""
+		}
+		return source.Grpc(ctx)
+	})
+	switch {
+	case errors.Is(err, errTenantDenied), errors.Is(err, errTenantClaim):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case tenant == "":
+		return ctx, nil
+	}
+	ctxzap.AddFields(ctx, zap.String("tenant", tenant))
+	return reqctx.Update(ctx, func(meta *reqctx.RequestMeta) {
+		meta.Tenant = tenant
+	}), nil
+}
+
+// Tenant returns a new unary server interceptor that reads the tenant of the call, from the
+// "tenant" claim of the verified JWT by default, and puts it in the context, see FromContext.
+// Run it after auth.Authenticate. Calls without a tenant fail with codes.InvalidArgument where one
+// is required, calls from a tenant outside of the allowed tenants of the method or other than the
+// tenant of their token fail with codes.PermissionDenied.
+// Run it before metrics.Measure when the metrics are labelled by tenant.
+func Tenant(opts ...Option) grpc.UnaryServerInterceptor {
+	o := evaluateOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		newCtx, err := o.resolveGrpc(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(newCtx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new streaming server interceptor that reads the tenant of the stream.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := evaluateOptions(opts)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		newCtx, err := o.resolveGrpc(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = newCtx
+		return handler(srv, wrapped)
+	}
+}
+
+// UnaryClientInterceptor returns a new unary client interceptor that forwards the tenant of the
+// context in the "x-tenant-id" metadata of outgoing calls. The called services read it with
+// WithSources(FromHeader(Header)).
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		if tenant := reqctx.Tenant(ctx); tenant != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, Header, tenant)
+		}
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+}
+
+// HttpTenant is a Gin middleware that reads the tenant of the request, from the "tenant" claim of
+// the verified JWT by default, and puts it in the request context, see FromContext.
+// Run it after auth.HttpAuthenticate. Requests without a tenant get 400 Bad Request where one is
+// required, requests from a tenant outside of the allowed tenants of the route or other than the
+// tenant of their token get 403 Forbidden.
+func HttpTenant(opts ...Option) gin.HandlerFunc {
+	o := evaluateOptions(opts)
+	return func(c *gin.Context) {
+		tenant, err := o.resolve(c.Request.Context(), c.Request.Method+" "+c.FullPath(), func(source Source) string {
+			if source.Http == nil {
+				return ""
+			}
+			return source.Http(c)
+		})
+		switch {
+		case errors.Is(err, errTenantDenied), errors.Is(err, errTenantClaim):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "Forbidden",
+				"details": err.Error(),
+			})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid tenant",
+				"details": err.Error(),
+			})
+			return
+		}
+		if tenant != "" {
+			c.Request = c.Request.WithContext(reqctx.Update(c.Request.Context(), func(meta *reqctx.RequestMeta) {
+				meta.Tenant = tenant
+			}))
+			interceptors.AddHttpLogFields(c, zap.String("tenant", tenant))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,210 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testMethod = "/order.OrderService/GetOrder"
+	testRoute  = "GET /orders"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func withClaim(ctx context.Context, tenant string) context.Context {
+	return auth.ContextWithClaims(ctx, &auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "alice"},
+		Extra:            map[string]interface{}{Claim: tenant},
+	})
+}
+
+var headerSource = WithSources(FromHeader(Header))
+
+type testCase struct {
+	name       string
+	opts       []Option
+	header     string
+	claim      string
+	wantCode   codes.Code
+	wantStatus int
+	wantTenant string
+}
+
+var tests = []testCase{
+	{name: "optional tenant missing", opts: []Option{headerSource}, wantCode: codes.OK, wantStatus: http.StatusOK},
+	{
+		name:       "required tenant missing",
+		opts:       []Option{headerSource, WithRequired()},
+		wantCode:   codes.InvalidArgument,
+		wantStatus: http.StatusBadRequest,
+	},
+	{
+		name:       "required for the method",
+		opts:       []Option{headerSource, WithRequired(testMethod, testRoute)},
+		wantCode:   codes.InvalidArgument,
+		wantStatus: http.StatusBadRequest,
+	},
+	{
+		name:       "required for another method",
+		opts:       []Option{headerSource, WithRequired("/order.OrderService/CreateOrder", "POST /orders")},
+		wantCode:   codes.OK,
+		wantStatus: http.StatusOK,
+	},
+	{
+		name:       "invalid tenant",
+		opts:       []Option{headerSource},
+		header:     "acme;drop",
+		wantCode:   codes.InvalidArgument,
+		wantStatus: http.StatusBadRequest,
+	},
+	{
+		name:       "custom validator",
+		opts:       []Option{headerSource, WithValidator(func(tenant string) bool { return tenant == "globex" })},
+		header:     "acme",
+		wantCode:   codes.InvalidArgument,
+		wantStatus: http.StatusBadRequest,
+	},
+	{
+		name:       "allowed tenant",
+		opts:       []Option{headerSource, WithAllowedTenants(testMethod, "acme"), WithAllowedTenants(testRoute, "acme")},
+		header:     "acme",
+		wantCode:   codes.OK,
+		wantStatus: http.StatusOK,
+		wantTenant: "acme",
+	},
+	{
+		name:       "tenant not allowed",
+		opts:       []Option{headerSource, WithAllowedTenants(testMethod, "acme"), WithAllowedTenants(testRoute, "acme")},
+		header:     "globex",
+		wantCode:   codes.PermissionDenied,
+		wantStatus: http.StatusForbidden,
+	},
+	{
+		name:       "allowed tenants require one",
+		opts:       []Option{headerSource, WithAllowedTenants(testMethod, "acme"), WithAllowedTenants(testRoute, "acme")},
+		wantCode:   codes.InvalidArgument,
+		wantStatus: http.StatusBadRequest,
+	},
+	{name: "tenant from claim by default", claim: "acme", wantCode: codes.OK, wantStatus: http.StatusOK, wantTenant: "acme"},
+	{name: "header ignored by default", header: "acme", wantCode: codes.OK, wantStatus: http.StatusOK},
+	{
+		name:       "header matching the claim",
+		opts:       []Option{headerSource},
+		header:     "acme",
+		claim:      "acme",
+		wantCode:   codes.OK,
+		wantStatus: http.StatusOK,
+		wantTenant: "acme",
+	},
+	{
+		name:       "header naming another tenant than the claim",
+		opts:       []Option{headerSource},
+		header:     "globex",
+		claim:      "acme",
+		wantCode:   codes.PermissionDenied,
+		wantStatus: http.StatusForbidden,
+	},
+	{
+		name:       "claim check disabled",
+		opts:       []Option{headerSource, WithClaim("")},
+		header:     "globex",
+		claim:      "acme",
+		wantCode:   codes.OK,
+		wantStatus: http.StatusOK,
+		wantTenant: "globex",
+	},
+}
+
+func TestTenant(t *testing.T) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(Header, tt.header))
+			}
+			if tt.claim != "" {
+				ctx = withClaim(ctx, tt.claim)
+			}
+			var tenant string
+			_, err := Tenant(tt.opts...)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				tenant = FromContext(ctx)
+				return nil, nil
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("code = %s, want %s (%v)", status.Code(err), tt.wantCode, err)
+			}
+			if tenant != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", tenant, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestHttpTenant(t *testing.T) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				if tt.claim != "" {
+					c.Request = c.Request.WithContext(withClaim(c.Request.Context(), tt.claim))
+				}
+			}, HttpTenant(tt.opts...))
+			var tenant string
+			r.GET("/orders", func(c *gin.Context) {
+				tenant = FromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.header != "" {
+				req.Header.Set(Header, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tenant != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", tenant, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestRequiredSkipsHealthChecks(t *testing.T) {
+	o := evaluateOptions([]Option{WithRequired()})
+	if o.requires("/grpc.health.v1.Health/Check") {
+		t.Error("health check requires a tenant")
+	}
+	if !o.requires(testMethod) {
+		t.Error("method does not require a tenant")
+	}
+}
+
+func TestUnaryClientInterceptorForwardsTenant(t *testing.T) {
+	ctx, err := evaluateOptions([]Option{headerSource}).resolveGrpc(
+		metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "acme")), testMethod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = UnaryClientInterceptor()(ctx, testMethod, nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if got := md.Get(Header); len(got) != 1 || got[0] != "acme" {
+			t.Errorf("outgoing %s = %v, want [acme]", Header, got)
+		}
+		return nil
+	})
+}
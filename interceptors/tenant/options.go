@@ -0,0 +1,137 @@
+package tenant
+
+import (
+	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors"
+)
+
+// Header carries the tenant ID, in gRPC metadata, HTTP headers and Kafka message headers.
+const Header = common.TenantIdKey
+
+// Claim is the JWT claim holding the tenant ID.
+const Claim = "tenant"
+
+var (
+	defaultOptions = &options{
+		sources:   []Source{FromClaim(Claim)},
+		claim:     Claim,
+		validator: DefaultValidator,
+	}
+)
+
+type options struct {
+	sources []Source
+	claim   string
+	// required is nil when no method requires a tenant and empty when every method does
+	required  map[string]struct{}
+	allowed   map[string]map[string]struct{}
+	validator func(tenant string) bool
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	*optCopy = *defaultOptions
+	optCopy.allowed = make(map[string]map[string]struct{})
+	for _, o := range opts {
+		o(optCopy)
+	}
+	return optCopy
+}
+
+// requires reports whether method rejects the requests without a tenant. Health checks and
+// reflection (interceptors.DefaultSkippedMethods) only require one when listed explicitly.
+func (o *options) requires(method string) bool {
+	if _, ok := o.allowed[method]; ok {
+		return true
+	}
+	if o.required == nil {
+		return false
+	}
+	if len(o.required) == 0 {
+		for _, skipped := range interceptors.DefaultSkippedMethods {
+			if method == skipped {
+				return false
+			}
+		}
+		return true
+	}
+	_, ok := o.required[method]
+	return ok
+}
+
+// permits reports whether tenant may call method.
+func (o *options) permits(method string, tenant string) bool {
+	allowed, ok := o.allowed[method]
+	if !ok {
+		return true
+	}
+	_, ok = allowed[tenant]
+	return ok
+}
+
+// DefaultValidator accepts tenant IDs of up to 64 letters, digits, '-' and '_'.
+func DefaultValidator(tenant string) bool {
+	if tenant == "" || len(tenant) > 64 {
+		return false
+	}
+	for _, r := range tenant {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type Option func(*options)
+
+// WithSources reads the tenant from the first of sources naming one, the "tenant" claim of the
+// verified JWT by default. See FromHeader before reading it from a client-supplied header.
+func WithSources(sources ...Source) Option {
+	return func(o *options) {
+		o.sources = sources
+	}
+}
+
+// WithRequired rejects the requests without a tenant to the given gRPC methods or gin routes
+// ("GET /orders"), to every method when none is given. Tenants are optional by default.
+func WithRequired(methods ...string) Option {
+	return func(o *options) {
+		if o.required == nil {
+			o.required = make(map[string]struct{}, len(methods))
+		}
+		for _, method := range methods {
+			o.required[method] = struct{}{}
+		}
+	}
+}
+
+// WithAllowedTenants only lets the given tenants call method, a gRPC method or gin route.
+// The method then also requires a tenant.
+func WithAllowedTenants(method string, tenants ...string) Option {
+	return func(o *options) {
+		if o.allowed[method] == nil {
+			o.allowed[method] = make(map[string]struct{}, len(tenants))
+		}
+		for _, tenant := range tenants {
+			o.allowed[method][tenant] = struct{}{}
+		}
+	}
+}
+
+// WithClaim sets the JWT claim the tenant of authenticated requests must match, "tenant" by default.
+// Requests naming another tenant than their token, in a header for instance, are rejected.
+// An empty name disables the check.
+func WithClaim(name string) Option {
+	return func(o *options) {
+		o.claim = name
+	}
+}
+
+// WithValidator customizes the check of the tenant IDs read from requests, DefaultValidator by default.
+func WithValidator(validator func(tenant string) bool) Option {
+	return func(o *options) {
+		o.validator = validator
+	}
+}
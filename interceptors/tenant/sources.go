@@ -0,0 +1,90 @@
+package tenant
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/auth"
+	"google.golang.org/grpc/metadata"
+)
+
+// Source reads the tenant of a request, for each transport. It returns an empty string when the
+// request does not name a tenant.
+type Source struct {
+	Grpc func(ctx context.Context) string
+	Http func(c *gin.Context) string
+}
+
+// FromHeader reads the tenant from a gRPC metadata key or HTTP header. Clients may set any header,
+// so only use it behind a trusted gateway that sets or strips it, or for calls between internal
+// services (see UnaryClientInterceptor). Tenants of authenticated requests must still match the
+// claim of their token, see WithClaim.
+func FromHeader(name string) Source {
+	return Source{
+		Grpc: func(ctx context.Context) string {
+			return firstMetadata(ctx, name)
+		},
+		Http: func(c *gin.Context) string {
+			return c.GetHeader(name)
+		},
+	}
+}
+
+// FromClaim reads the tenant from a string claim of the JWT verified by auth.Authenticate or
+// auth.HttpAuthenticate, which must run before the tenant interceptors.
+func FromClaim(name string) Source {
+	claim := func(ctx context.Context) string {
+		claims, ok := auth.ClaimsFromContext(ctx)
+		if !ok {
+			return ""
+		}
+		return claims.String(name)
+	}
+	return Source{
+		Grpc: claim,
+		Http: func(c *gin.Context) string {
+			return claim(c.Request.Context())
+		},
+	}
+}
+
+// FromSubdomain reads the tenant from the first label of the host under baseDomain, "acme" for
+// "acme.api.example.com" with the base domain "api.example.com". The gRPC host is the ":authority".
+func FromSubdomain(baseDomain string) Source {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+	subdomain := func(host string) string {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(host)
+		if !strings.HasSuffix(host, suffix) {
+			return ""
+		}
+		label := strings.TrimSuffix(host, suffix)
+		if strings.Contains(label, ".") {
+			return ""
+		}
+		return label
+	}
+	return Source{
+		Grpc: func(ctx context.Context) string {
+			return subdomain(firstMetadata(ctx, ":authority"))
+		},
+		Http: func(c *gin.Context) string {
+			return subdomain(c.Request.Host)
+		},
+	}
+}
+
+func firstMetadata(ctx context.Context, name string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
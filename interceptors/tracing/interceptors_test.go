@@ -0,0 +1,51 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/tracing"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/tracing/tracingtest"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTraceContinuesTraceparent(t *testing.T) {
+	exporter := tracingtest.NewMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	interceptor := tracing.Trace(tracing.WithTracerProvider(provider), tracing.WithPropagator(propagation.TraceContext{}))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", testTraceparent))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUser"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Internal, "failed")
+		})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("code = %s, want Internal", status.Code(err))
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "user.UserService/GetUser" || span.SpanKind != trace.SpanKindServer {
+		t.Errorf("span = %s %s, want server span user.UserService/GetUser", span.SpanKind, span.Name)
+	}
+	if got := span.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want the one of the traceparent", got)
+	}
+	if got := span.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span id = %s, want the one of the traceparent", got)
+	}
+	if span.Status.Description != "failed" {
+		t.Errorf("status = %+v, want the error of the handler", span.Status)
+	}
+}
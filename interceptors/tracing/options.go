@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+func evaluateOptions(opts []Option) *options {
+	optCopy := &options{}
+	for _, o := range opts {
+		o(optCopy)
+	}
+	if optCopy.tracerProvider == nil {
+		optCopy.tracerProvider = otel.GetTracerProvider()
+	}
+	if optCopy.propagator == nil {
+		optCopy.propagator = otel.GetTextMapPropagator()
+	}
+	return optCopy
+}
+
+func (o *options) tracer() trace.Tracer {
+	return o.tracerProvider.Tracer(instrumentationName)
+}
+
+type Option func(*options)
+
+// WithTracerProvider creates the spans with tracerProvider instead of the global one.
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = tracerProvider
+	}
+}
+
+// WithPropagator propagates the trace context with propagator instead of the global one.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(o *options) {
+		o.propagator = propagator
+	}
+}
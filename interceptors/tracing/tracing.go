@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// instrumentationName names the tracer of the interceptors.
+const instrumentationName = "github.com/tqhuy-dev/xgen-uranus/interceptors/tracing"
+
+// Setup installs a tracer provider exporting the spans of appName to exporter and the W3C
+// traceparent/baggage propagator as OpenTelemetry globals. Shut the provider down on exit
+// to flush the remaining spans.
+func Setup(appName string, exporter sdktrace.SpanExporter, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", appName)))
+	if err != nil {
+		res = resource.Default()
+	}
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	}, opts...)
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider
+}
+
+// NewStdoutExporter returns an exporter writing the spans as indented JSON to w, os.Stdout when nil.
+func NewStdoutExporter(w io.Writer) sdktrace.SpanExporter {
+	opts := []stdouttrace.Option{stdouttrace.WithPrettyPrint()}
+	if w != nil {
+		opts = append(opts, stdouttrace.WithWriter(w))
+	}
+	// stdouttrace.New only fails on invalid options
+	exporter, _ := stdouttrace.New(opts...)
+	return exporter
+}
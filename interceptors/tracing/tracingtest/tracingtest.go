@@ -0,0 +1,11 @@
+package tracingtest
+
+import (
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NewMemoryExporter returns an exporter keeping the spans of the tracing interceptors in memory,
+// to inspect them in tests. Call ForceFlush on the provider before reading its spans.
+func NewMemoryExporter() *tracetest.InMemoryExporter {
+	return tracetest.NewInMemoryExporter()
+}
@@ -2,7 +2,9 @@ package interceptors
 
 import (
 	"context"
+	"errors"
 
+	uranuserrors "github.com/tqhuy-dev/xgen-uranus/errors"
 	"google.golang.org/grpc"
 )
 
@@ -10,12 +12,88 @@ type IValidator interface {
 	Validate() error
 }
 
+// IValidatorAll is implemented by the messages generated by protoc-gen-validate,
+// ValidateAll reports every violation instead of the first one.
+type IValidatorAll interface {
+	ValidateAll() error
+}
+
+// ValidationFailedReason is the reason of the errors returned for invalid requests.
+const ValidationFailedReason = "VALIDATION_FAILED"
+
+// fieldError is implemented by the validation errors generated by protoc-gen-validate.
+type fieldError interface {
+	Field() string
+	Reason() string
+}
+
+// multiError is implemented by the multi-errors returned by ValidateAll.
+type multiError interface {
+	AllErrors() []error
+}
+
+// validate validates req with ValidateAll when available, Validate otherwise.
+func validate(req interface{}) error {
+	if v, ok := req.(IValidatorAll); ok {
+		return v.ValidateAll()
+	}
+	if v, ok := req.(IValidator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// FieldViolations returns the field violations of a validation error. Violations of nested messages
+// are reported with their full path ("address.zip_code"). Errors that are not protoc-gen-validate
+// errors are reported as a violation without field.
+func FieldViolations(err error) []uranuserrors.FieldViolation {
+	return appendViolations(nil, "", err)
+}
+
+func appendViolations(violations []uranuserrors.FieldViolation, prefix string, err error) []uranuserrors.FieldViolation {
+	if err == nil {
+		return violations
+	}
+	var multi multiError
+	if errors.As(err, &multi) {
+		for _, e := range multi.AllErrors() {
+			violations = appendViolations(violations, prefix, e)
+		}
+		return violations
+	}
+	var fe fieldError
+	if !errors.As(err, &fe) {
+		return append(violations, uranuserrors.FieldViolation{Field: prefix, Description: err.Error()})
+	}
+	field := fe.Field()
+	if prefix != "" {
+		field = prefix + "." + field
+	}
+	// violations of embedded messages are the cause of the error of their field
+	if causer, ok := fe.(interface{ Cause() error }); ok {
+		var nested fieldError
+		var nestedMulti multiError
+		if cause := causer.Cause(); errors.As(cause, &nested) || errors.As(cause, &nestedMulti) {
+			return appendViolations(violations, field, cause)
+		}
+	}
+	return append(violations, uranuserrors.FieldViolation{Field: field, Description: fe.Reason()})
+}
+
+// validationError returns the bad request error of a validation error, listing its field violations.
+// It is codes.InvalidArgument with an ErrorInfo and a BadRequest detail in gRPC.
+func validationError(err error) *uranuserrors.Error {
+	return uranuserrors.BadRequest(ValidationFailedReason, err.Error()).
+		WithViolations(FieldViolations(err)...).
+		WithCause(err)
+}
+
+// Validators returns a new unary server interceptor that rejects the requests failing their validation
+// with codes.InvalidArgument and a BadRequest detail listing every field violation.
 func Validators() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		if reqValidator, ok := req.(IValidator); ok {
-			if err := reqValidator.Validate(); err != nil {
-				return nil, err
-			}
+		if err := validate(req); err != nil {
+			return nil, validationError(err)
 		}
 		resp, err := handler(ctx, req)
 		return resp, err
@@ -1,6 +1,7 @@
 package kafka_provider
 
 import (
+	"context"
 	"log"
 	"time"
 
@@ -71,13 +72,19 @@ func (p *AsyncProducer) Run() {
 }
 
 func (p *AsyncProducer) SendMessages(topic string, key string, data []byte) error {
+	return p.SendMessagesWithContext(context.Background(), topic, key, data)
+}
 
+// SendMessagesWithContext sends a message continuing the trace of ctx, its traceparent is added to the message headers.
+func (p *AsyncProducer) SendMessagesWithContext(ctx context.Context, topic string, key string, data []byte) error {
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
 		Key:   sarama.StringEncoder(key),
 		Value: sarama.ByteEncoder(data),
 	}
+	span := startProducerSpan(ctx, msg)
 	p.Input() <- msg
+	endSpan(span, nil)
 
 	return nil
 }
@@ -10,6 +10,14 @@ import (
 	"syscall"
 
 	"github.com/IBM/sarama"
+	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/tenant"
+	"github.com/tqhuy-dev/xgen-uranus/logging"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 type IConsumerService interface {
@@ -57,6 +65,8 @@ type ConsumerApp struct {
 	consumerGroup      sarama.ConsumerGroup
 	handler            map[string]IConsumerService
 	consumerMsgHandler *ConsumerMessageHandle
+	zapLog             *zap.Logger
+	appName            string
 }
 
 func NewConsumerApp(config ConsumerConfig) *ConsumerApp {
@@ -65,11 +75,13 @@ func NewConsumerApp(config ConsumerConfig) *ConsumerApp {
 	if err != nil {
 		panic(err)
 	}
+	zapLog, _ := zap.NewProduction()
 	return &ConsumerApp{
 		Config:        config,
 		ready:         make(chan bool),
 		consumerGroup: client,
 		handler:       make(map[string]IConsumerService),
+		zapLog:        zapLog,
 		consumerMsgHandler: &ConsumerMessageHandle{
 			fHandlerError: func(error) {},
 			fReceive: func(message *sarama.ConsumerMessage) {
@@ -196,7 +208,10 @@ func (c *ConsumerApp) ConsumeClaim(session sarama.ConsumerGroupSession, claim sa
 			if handlerService, valid := c.handler[message.Topic]; !valid || handlerService == nil {
 				continue
 			} else {
-				err := handlerService.HandleMessage(context.Background(), message)
+				// the handler continues the trace of the producer, see SendMessagesWithContext
+				ctx, span := startConsumerSpan(context.Background(), message)
+				err := handlerService.HandleMessage(c.messageContext(ctx, message), message)
+				endSpan(span, err)
 				if err != nil {
 					c.consumerMsgHandler.fHandlerError(err)
 				}
@@ -210,6 +225,52 @@ func (c *ConsumerApp) ConsumeClaim(session sarama.ConsumerGroupSession, claim sa
 	}
 }
 
+// messageContext returns the context handling message, carrying a reqctx.RequestMeta with the correlation ID
+// and tenant of its headers and a logger for logging.FromContext. Like interceptors.CorrelationTracing, a
+// missing or invalid correlation ID is replaced by the trace ID of ctx or a generated one, and a tenant
+// rejected by tenant.DefaultValidator is dropped.
+func (c *ConsumerApp) messageContext(ctx context.Context, message *sarama.ConsumerMessage) context.Context {
+	meta := reqctx.RequestMeta{Route: message.Topic}
+	for _, h := range message.Headers {
+		if h == nil || len(h.Value) == 0 {
+			continue
+		}
+		switch value := string(h.Value); string(h.Key) {
+		case common.CorrelationIdKey:
+			if interceptors.DefaultCorrelationIdValidator(value) {
+				meta.CorrelationId = value
+			}
+		case common.TenantIdKey:
+			if tenant.DefaultValidator(value) {
+				meta.Tenant = value
+			}
+		}
+	}
+	if meta.CorrelationId == "" {
+		meta.CorrelationId = interceptors.DefaultCorrelationIdGenerator(ctx)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String(common.CorrelationIdKey, meta.CorrelationId))
+	ctx = reqctx.WithMeta(ctx, meta)
+	return logging.ToContext(ctx, c.zapLog.With(
+		zap.String("topic", message.Topic),
+		zap.Int32("partition", message.Partition),
+		zap.Int64("offset", message.Offset),
+		zap.String(common.LogKeyAppName, c.appName),
+	))
+}
+
+// WithZapLog sets the logger handed to the handlers through logging.FromContext.
+func (c *ConsumerApp) WithZapLog(zapLog *zap.Logger) *ConsumerApp {
+	c.zapLog = zapLog
+	return c
+}
+
+// WithAppName sets the app name logged by the handlers.
+func (c *ConsumerApp) WithAppName(appName string) *ConsumerApp {
+	c.appName = appName
+	return c
+}
+
 func (c *ConsumerApp) RegisterHandler(topic string, handler IConsumerService) *ConsumerApp {
 	c.handler[topic] = handler
 	return c
@@ -0,0 +1,77 @@
+package kafka_provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const testTraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+func tracedContext(t *testing.T) context.Context {
+	t.Helper()
+	traceId, err := trace.TraceIDFromHex(testTraceId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceId,
+		SpanID:  trace.SpanID{1},
+	}))
+}
+
+func TestMessageContext(t *testing.T) {
+	tests := []struct {
+		name              string
+		traced            bool
+		correlationId     string
+		tenant            string
+		wantCorrelationId string
+		wantTenant        string
+	}{
+		{name: "headers", correlationId: "req-1", tenant: "acme", wantCorrelationId: "req-1", wantTenant: "acme"},
+		{name: "trace id without correlation header", traced: true, wantCorrelationId: testTraceId},
+		{name: "injected correlation id replaced", traced: true, correlationId: "req\r\n1", wantCorrelationId: testTraceId},
+		{name: "invalid tenant dropped", correlationId: "req-1", tenant: "acme;drop", wantCorrelationId: "req-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := &sarama.ConsumerMessage{Topic: "orders"}
+			if tt.correlationId != "" {
+				message.Headers = append(message.Headers, &sarama.RecordHeader{Key: []byte(common.CorrelationIdKey), Value: []byte(tt.correlationId)})
+			}
+			if tt.tenant != "" {
+				message.Headers = append(message.Headers, &sarama.RecordHeader{Key: []byte(common.TenantIdKey), Value: []byte(tt.tenant)})
+			}
+			ctx := context.Background()
+			if tt.traced {
+				ctx = tracedContext(t)
+			}
+
+			meta, _ := reqctx.FromContext((&ConsumerApp{zapLog: zap.NewNop()}).messageContext(ctx, message))
+			if meta.CorrelationId != tt.wantCorrelationId {
+				t.Errorf("correlation id = %q, want %q", meta.CorrelationId, tt.wantCorrelationId)
+			}
+			if meta.Tenant != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", meta.Tenant, tt.wantTenant)
+			}
+			if meta.Route != "orders" {
+				t.Errorf("route = %q, want the topic", meta.Route)
+			}
+		})
+	}
+}
+
+func TestMessageContextGeneratesCorrelationId(t *testing.T) {
+	app := &ConsumerApp{zapLog: zap.NewNop()}
+	first := reqctx.CorrelationId(app.messageContext(context.Background(), &sarama.ConsumerMessage{Topic: "orders"}))
+	second := reqctx.CorrelationId(app.messageContext(context.Background(), &sarama.ConsumerMessage{Topic: "orders"}))
+	if first == "" || first == second {
+		t.Errorf("correlation ids = %q and %q, want distinct generated ones", first, second)
+	}
+}
@@ -1,6 +1,10 @@
 package kafka_provider
 
-import "github.com/IBM/sarama"
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+)
 
 type SyncProducer struct {
 	sarama.SyncProducer
@@ -19,10 +23,18 @@ func (p *SyncProducer) Run() {
 }
 
 func (p *SyncProducer) SendMessages(topic string, key string, data []byte) (int32, int64, error) {
-	partition, offset, err := p.SyncProducer.SendMessage(&sarama.ProducerMessage{
+	return p.SendMessagesWithContext(context.Background(), topic, key, data)
+}
+
+// SendMessagesWithContext sends a message continuing the trace of ctx, its traceparent is added to the message headers.
+func (p *SyncProducer) SendMessagesWithContext(ctx context.Context, topic string, key string, data []byte) (int32, int64, error) {
+	msg := &sarama.ProducerMessage{
 		Topic: topic,
 		Key:   sarama.StringEncoder(key),
 		Value: sarama.ByteEncoder(data),
-	})
+	}
+	span := startProducerSpan(ctx, msg)
+	partition, offset, err := p.SyncProducer.SendMessage(msg)
+	endSpan(span, err)
 	return partition, offset, err
 }
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tqhuy-dev/xgen-uranus/reqctx"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type loggerKey struct{}
+
+// ToContext returns a copy of ctx carrying logger, the interceptors store the per-call logger with it.
+func ToContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger of the call handled with ctx, enriched with its correlation ID, tenant and trace.
+// It works with the context of gRPC handlers, gin handlers (*gin.Context or c.Request.Context())
+// and IConsumerService.HandleMessage. Outside of a call it returns the global zap logger, see zap.ReplaceGlobals.
+//
+// Usage in handler:
+//
+//	logging.FromContext(ctx).Info("user created", zap.String("user_id", id))
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx == nil {
+		return zap.L()
+	}
+	correlationId := reqctx.CorrelationId(ctx)
+	tenant := reqctx.Tenant(ctx)
+	if c, ok := ctx.(*gin.Context); ok && c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	logger, ok := ctx.Value(loggerKey{}).(*zap.Logger)
+	if !ok || logger == nil {
+		logger = zap.L()
+	}
+	var fields []zap.Field
+	if correlationId != "" {
+		fields = append(fields, zap.String("correlation_id", correlationId))
+	}
+	if tenant != "" {
+		fields = append(fields, zap.String("tenant", tenant))
+	}
+	return logger.With(append(fields, TraceFields(ctx)...)...)
+}
+
+// TraceFields returns the trace_id and span_id fields of ctx, none when ctx is not traced.
+func TraceFields(ctx context.Context) []zap.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", spanContext.TraceID().String()),
+		zap.String("span_id", spanContext.SpanID().String()),
+	}
+}
@@ -0,0 +1,74 @@
+package reqctx
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tqhuy-dev/xgen-uranus/common"
+)
+
+// RequestMeta describes the request handled with a context, whatever its transport.
+// It is populated by the correlation, authentication and tenant interceptors of gRPC and gin alike.
+type RequestMeta struct {
+	CorrelationId string
+	ClientIP      string
+	UserAgent     string
+	// Subject is the authenticated caller, empty for anonymous requests.
+	Subject string
+	Tenant  string
+	// Route is the full gRPC method ("/pkg.Service/Method") or the gin route ("GET /users/:id").
+	Route string
+}
+
+type metaKey struct{}
+
+// WithMeta returns a copy of ctx carrying meta.
+func WithMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// Update returns a copy of ctx whose RequestMeta is modified by update.
+func Update(ctx context.Context, update func(meta *RequestMeta)) context.Context {
+	meta, _ := FromContext(ctx)
+	update(&meta)
+	return WithMeta(ctx, meta)
+}
+
+// FromContext returns the RequestMeta of ctx. A *gin.Context is read through its request context.
+func FromContext(ctx context.Context) (RequestMeta, bool) {
+	if ctx == nil {
+		return RequestMeta{}, false
+	}
+	if c, ok := ctx.(*gin.Context); ok && c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	meta, ok := ctx.Value(metaKey{}).(RequestMeta)
+	return meta, ok
+}
+
+// CorrelationId returns the correlation ID of ctx, also when it was set with the common.CorrelationIdKey string key.
+func CorrelationId(ctx context.Context) string {
+	if meta, ok := FromContext(ctx); ok && meta.CorrelationId != "" {
+		return meta.CorrelationId
+	}
+	if ctx == nil {
+		return ""
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		return c.GetString(common.CorrelationIdKey)
+	}
+	id, _ := ctx.Value(common.CorrelationIdKey).(string)
+	return id
+}
+
+// Subject returns the authenticated subject of ctx.
+func Subject(ctx context.Context) string {
+	meta, _ := FromContext(ctx)
+	return meta.Subject
+}
+
+// Tenant returns the tenant of ctx.
+func Tenant(ctx context.Context) string {
+	meta, _ := FromContext(ctx)
+	return meta.Tenant
+}
@@ -0,0 +1,66 @@
+package grpc
+
+import (
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/retry"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type Client struct {
+	*grpc.ClientConn
+	option clientOption
+}
+
+// NewClient creates a client connection to target. Retries configured with WithRetryConfig
+// run before the user interceptors, so every attempt goes through them.
+func NewClient(target string, opts ...IOptionClient) (*Client, error) {
+	opt := clientOption{}
+	for _, o := range opts {
+		o.Apply(&opt)
+	}
+	if opt.zapLog == nil {
+		opt.zapLog, _ = zap.NewProduction()
+	}
+	if err := opt.retryConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	dialOptions := []grpc.DialOption{
+		// retries are handled by the retry interceptor, disable the built-in ones to avoid multiplying attempts
+		grpc.WithDisableRetry(),
+	}
+	if opt.creds != nil {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(opt.creds))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	if len(opt.resolvers) > 0 {
+		dialOptions = append(dialOptions, grpc.WithResolvers(opt.resolvers...))
+	}
+	if opt.balancer != "" {
+		dialOptions = append(dialOptions, grpc.WithDefaultServiceConfig(opt.balancer.ServiceConfig()))
+	}
+
+	var unaryInterceptors []grpc.UnaryClientInterceptor
+	if opt.retryConfig != nil {
+		unaryInterceptors = append(unaryInterceptors, retry.UnaryClientInterceptor(
+			retry.WithConfig(opt.retryConfig),
+			retry.WithZapLog(opt.zapLog),
+			retry.WithAppName(opt.appName),
+		))
+	}
+	unaryInterceptors = append(unaryInterceptors, opt.unaryInterceptors...)
+	dialOptions = append(dialOptions,
+		grpc.WithChainUnaryInterceptor(unaryInterceptors...),
+		grpc.WithChainStreamInterceptor(opt.streamInterceptors...),
+	)
+	dialOptions = append(dialOptions, opt.dialOptions...)
+
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ClientConn: conn, option: opt}, nil
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"github.com/tqhuy-dev/xgen-uranus/discovery"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/retry"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/resolver"
+)
+
+type IOptionClient interface {
+	Apply(*clientOption)
+}
+
+type clientOptionFunc func(*clientOption)
+
+func (f clientOptionFunc) Apply(o *clientOption) { f(o) }
+
+// WithClientAppName sets the app name reported in the client logs.
+func WithClientAppName(appName string) IOptionClient {
+	return clientOptionFunc(func(o *clientOption) {
+		o.appName = appName
+	})
+}
+
+func WithClientZapLog(zapLog *zap.Logger) IOptionClient {
+	return clientOptionFunc(func(o *clientOption) {
+		o.zapLog = zapLog
+	})
+}
+
+// WithRetryConfig applies per-method retry and hedging policies to the client.
+func WithRetryConfig(config *retry.Config) IOptionClient {
+	return clientOptionFunc(func(o *clientOption) {
+		o.retryConfig = config
+	})
+}
+
+// WithResolvers registers resolvers for this client only, e.g. discovery.NewFileBuilder().
+func WithResolvers(builders ...resolver.Builder) IOptionClient {
+	return clientOptionFunc(func(o *clientOption) {
+		o.resolvers = append(o.resolvers, builders...)
+	})
+}
+
+// WithBalancer sets the load balancing policy across the resolved endpoints.
+func WithBalancer(balancer discovery.Balancer) IOptionClient {
+	return clientOptionFunc(func(o *clientOption) {
+		o.balancer = balancer
+	})
+}
+
+// WithTransportCredentials overrides the default insecure transport credentials.
+func WithTransportCredentials(creds credentials.TransportCredentials) IOptionClient {
+	return clientOptionFunc(func(o *clientOption) {
+		o.creds = creds
+	})
+}
+
+func WithClientUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) IOptionClient {
+	return clientOptionFunc(func(o *clientOption) {
+		o.unaryInterceptors = interceptors
+	})
+}
+
+func WithClientStreamInterceptors(interceptors ...grpc.StreamClientInterceptor) IOptionClient {
+	return clientOptionFunc(func(o *clientOption) {
+		o.streamInterceptors = interceptors
+	})
+}
+
+// WithDialOptions appends raw gRPC dial options.
+func WithDialOptions(dialOptions ...grpc.DialOption) IOptionClient {
+	return clientOptionFunc(func(o *clientOption) {
+		o.dialOptions = append(o.dialOptions, dialOptions...)
+	})
+}
+
+type clientOption struct {
+	appName            string
+	zapLog             *zap.Logger
+	retryConfig        *retry.Config
+	resolvers          []resolver.Builder
+	balancer           discovery.Balancer
+	creds              credentials.TransportCredentials
+	unaryInterceptors  []grpc.UnaryClientInterceptor
+	streamInterceptors []grpc.StreamClientInterceptor
+	dialOptions        []grpc.DialOption
+}
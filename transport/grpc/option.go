@@ -43,10 +43,20 @@ func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) IOptionG
 	})
 }
 
+// WithStreamInterceptors installs the streaming server interceptors, in order. Streaming RPCs only go
+// through these, install the StreamServerInterceptor of the auth, rate limit and recovery packages here
+// alongside their unary interceptors.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) IOptionGrpc {
+	return optionFunc(func(o *option) {
+		o.streamInterceptors = interceptors
+	})
+}
+
 type option struct {
-	port              int
-	useReflection     bool
-	appName           string
-	zapLog            *zap.Logger
-	unaryInterceptors []grpc.UnaryServerInterceptor
+	port               int
+	useReflection      bool
+	appName            string
+	zapLog             *zap.Logger
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
 }
@@ -26,7 +26,10 @@ func NewServer(opts ...IOptionGrpc) *Server {
 	if opt.zapLog == nil {
 		opt.zapLog, _ = zap.NewProduction()
 	}
-	server := grpc.NewServer(grpc.ChainUnaryInterceptor(opt.unaryInterceptors...))
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(opt.unaryInterceptors...),
+		grpc.ChainStreamInterceptor(opt.streamInterceptors...),
+	)
 	return &Server{Server: server, option: opt}
 }
 
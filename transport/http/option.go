@@ -1,6 +1,11 @@
 package http
 
-import "github.com/gin-gonic/gin"
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
 
 type IOptionGrpc interface {
 	Apply(*option)
@@ -17,10 +22,35 @@ func WithConnectorOption(port int, appName string) IOptionGrpc {
 	})
 }
 
+// TimeoutOption configures the timeouts of the server, see http.Server. Zero values keep the defaults.
+type TimeoutOption struct {
+	ReadHeader time.Duration
+	Read       time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
 type option struct {
-	port         int
-	appName      string
-	interceptors []gin.HandlerFunc
+	port           int
+	appName        string
+	interceptors   []gin.HandlerFunc
+	timeouts       TimeoutOption
+	maxHeaderBytes int
+	ginMode        string
+	trustedProxies []string
+	bareEngine     bool
+}
+
+// defaultOption protects the server against slow clients (slowloris) and recovers the panics of handlers
+// with recovery.HttpRecovery, trusting no proxy.
+var defaultOption = option{
+	timeouts: TimeoutOption{
+		ReadHeader: 10 * time.Second,
+		Read:       30 * time.Second,
+		Write:      30 * time.Second,
+		Idle:       120 * time.Second,
+	},
+	maxHeaderBytes: http.DefaultMaxHeaderBytes,
 }
 
 func WithInterceptors(interceptors ...gin.HandlerFunc) IOptionGrpc {
@@ -28,3 +58,54 @@ func WithInterceptors(interceptors ...gin.HandlerFunc) IOptionGrpc {
 		o.interceptors = interceptors
 	})
 }
+
+// WithTimeouts overrides the non-zero timeouts of opt. By default headers must be read within 10s,
+// requests within 30s, responses written within 30s and idle connections are closed after 120s.
+func WithTimeouts(opt TimeoutOption) IOptionGrpc {
+	return optionFunc(func(o *option) {
+		if opt.ReadHeader > 0 {
+			o.timeouts.ReadHeader = opt.ReadHeader
+		}
+		if opt.Read > 0 {
+			o.timeouts.Read = opt.Read
+		}
+		if opt.Write > 0 {
+			o.timeouts.Write = opt.Write
+		}
+		if opt.Idle > 0 {
+			o.timeouts.Idle = opt.Idle
+		}
+	})
+}
+
+// WithMaxHeaderBytes caps the size of request headers, http.DefaultMaxHeaderBytes (1MB) by default.
+func WithMaxHeaderBytes(maxHeaderBytes int) IOptionGrpc {
+	return optionFunc(func(o *option) {
+		o.maxHeaderBytes = maxHeaderBytes
+	})
+}
+
+// WithGinMode sets the gin mode (gin.ReleaseMode, gin.DebugMode or gin.TestMode) when the server is created.
+// The mode is global to the process, it is left to gin (the GIN_MODE environment variable) without this option.
+func WithGinMode(mode string) IOptionGrpc {
+	return optionFunc(func(o *option) {
+		o.ginMode = mode
+	})
+}
+
+// WithTrustedProxies trusts the X-Forwarded-For header of the given proxy IPs or CIDRs for c.ClientIP().
+// No proxy is trusted by default, the client IP is the remote address.
+func WithTrustedProxies(proxies ...string) IOptionGrpc {
+	return optionFunc(func(o *option) {
+		o.trustedProxies = proxies
+	})
+}
+
+// WithBareEngine starts from an engine without middlewares when bare, so that panics are only recovered
+// by the interceptors, a recovery.HttpRecovery with custom options for instance. By default the engine
+// recovers panics with recovery.HttpRecovery before running the interceptors.
+func WithBareEngine(bare bool) IOptionGrpc {
+	return optionFunc(func(o *option) {
+		o.bareEngine = bare
+	})
+}
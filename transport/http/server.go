@@ -7,6 +7,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/tqhuy-dev/xgen-uranus/common"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/metrics"
+	"github.com/tqhuy-dev/xgen-uranus/interceptors/recovery"
 	"go.uber.org/zap"
 )
 
@@ -19,16 +21,30 @@ type Server struct {
 }
 
 func NewServer(opts ...IOptionGrpc) *Server {
-	opt := option{}
+	opt := defaultOption
 	for _, o := range opts {
 		o.Apply(&opt)
 	}
 
-	r := gin.Default()
+	if opt.ginMode != "" {
+		gin.SetMode(opt.ginMode)
+	}
+	r := gin.New()
+	if !opt.bareEngine {
+		r.Use(recovery.HttpRecovery(recovery.WithAppName(opt.appName)))
+	}
+	if err := r.SetTrustedProxies(opt.trustedProxies); err != nil {
+		panic(err)
+	}
 	r.Use(opt.interceptors...)
 	return &Server{Server: &http.Server{
-		Addr:    fmt.Sprintf(":%d", opt.port),
-		Handler: r,
+		Addr:              fmt.Sprintf(":%d", opt.port),
+		Handler:           r,
+		ReadHeaderTimeout: opt.timeouts.ReadHeader,
+		ReadTimeout:       opt.timeouts.Read,
+		WriteTimeout:      opt.timeouts.Write,
+		IdleTimeout:       opt.timeouts.Idle,
+		MaxHeaderBytes:    opt.maxHeaderBytes,
 	}, option: opt, ginEngine: r}
 }
 
@@ -43,6 +59,12 @@ func (s *Server) HealthCheck(path string) *Server {
 	return s
 }
 
+// Metrics exposes m on /metrics of the server. Use metrics.AdminServer to expose it on another port instead.
+func (s *Server) Metrics(m *metrics.Metrics) *Server {
+	s.ginEngine.GET(metrics.Path, gin.WrapH(m.Handler()))
+	return s
+}
+
 func (s *Server) RegisterRouter(registerFunc func(r *gin.Engine)) *Server {
 	registerFunc(s.ginEngine)
 	return s
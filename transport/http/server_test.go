@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewServerRecoversPanics(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []IOptionGrpc
+		wantPanic bool
+	}{
+		{name: "recovered by default"},
+		{name: "bare engine", opts: []IOptionGrpc{WithBareEngine(true)}, wantPanic: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(append(tt.opts, WithGinMode(gin.TestMode))...).RegisterRouter(func(r *gin.Engine) {
+				r.GET("/panic", func(c *gin.Context) {
+					panic("boom")
+				})
+			})
+			defer func() {
+				if p := recover(); (p != nil) != tt.wantPanic {
+					t.Errorf("panic = %v, want panic %t", p, tt.wantPanic)
+				}
+			}()
+			w := httptest.NewRecorder()
+			s.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+			if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+				t.Errorf("response = %d %s, want a 500 JSON body", w.Code, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
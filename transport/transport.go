@@ -3,6 +3,7 @@ package transport
 import (
 	"context"
 	"fmt"
+	stdhttp "net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -18,6 +19,7 @@ import (
 type UranusServer struct {
 	httpServer     *http.Server
 	grpcServer     *grpc.Server
+	adminServer    *stdhttp.Server
 	shutdownOption common.GracefulShutdown
 	zapLog         *zap.Logger
 }
@@ -36,6 +38,12 @@ func (s *UranusServer) WithGrpcServer(server *grpc.Server) *UranusServer {
 	return s
 }
 
+// WithAdminServer runs an internal server next to the others, e.g. metrics.AdminServer.
+func (s *UranusServer) WithAdminServer(server *stdhttp.Server) *UranusServer {
+	s.adminServer = server
+	return s
+}
+
 func (s *UranusServer) WithGracefulShutdown(shutdownOption common.GracefulShutdown) *UranusServer {
 	s.shutdownOption = shutdownOption
 	return s
@@ -62,6 +70,12 @@ func (s *UranusServer) Run() {
 			s.httpServer.StartHttpServer()
 		}
 	}()
+	go func() {
+		if s.adminServer != nil {
+			s.zapLog.Info("admin server listen on", zap.String("addr", s.adminServer.Addr))
+			_ = s.adminServer.ListenAndServe()
+		}
+	}()
 	<-signalCtx.Done()
 	if s.httpServer != nil {
 		s.httpServer.SwitchHealthCheck(false)
@@ -92,6 +106,16 @@ func (s *UranusServer) Run() {
 		}
 	})
 
+	wg.Go(func() {
+		if s.adminServer != nil {
+			if err := s.adminServer.Shutdown(context.Background()); err != nil {
+				s.zapLog.Error("admin server shutdown error", zap.Error(err))
+			} else {
+				s.zapLog.Info("admin server stopped")
+			}
+		}
+	})
+
 	wg.Wait()
 	s.zapLog.Info("shutdown complete")
 